@@ -3,6 +3,7 @@ package benchmark
 import (
 	"accountapi-lib-form3/pkg/api_client"
 	"accountapi-lib-form3/pkg/configuration"
+	"accountapi-lib-form3/pkg/logging"
 	"accountapi-lib-form3/pkg/models"
 	"bytes"
 	"io/ioutil"
@@ -68,3 +69,27 @@ func BenchmarkWithVerbose(b *testing.B) {
 		_, _ = account.FetchAccount(&req)
 	}
 }
+
+// BenchmarkWithLogger allows to determine impact that logging a structured entry per request has, verbose
+// is not enabled, so headers and bodies are neither read nor formatted.
+func BenchmarkWithLogger(b *testing.B) {
+	req := models.FetchRequest{
+		AccountId: "ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6",
+	}
+
+	client := &http.Client{
+		Transport: &TransportFake{},
+	}
+
+	subject := configuration.NewDefaultConfigBuilder().
+		WithHost("fake").
+		WithHttpClient(client).
+		WithLogger(logging.NewJSONLogger(ioutil.Discard, logging.LevelInfo)).
+		Build()
+
+	account := api_client.NewAccountService(&subject)
+
+	for i := 0; i < b.N; i++ {
+		_, _ = account.FetchAccount(&req)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"accountapi-lib-form3/pkg/api_client"
+	"accountapi-lib-form3/pkg/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stringList is a flag that can be repeated, every value is appended.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func runCreate(c *cli, args []string) error {
+	flags := c.newFlagSet("create")
+	file := flags.String("file", "", "JSON create request, - reads it from stdin, other flags are ignored")
+	id := flags.String("id", "", "account ID, generated when omitted")
+	organisationID := flags.String("organisation-id", "", "organisation ID")
+	country := flags.String("country", "", "ISO 3166-1 country code")
+	baseCurrency := flags.String("base-currency", "", "ISO 4217 currency code")
+	bankID := flags.String("bank-id", "", "bank ID")
+	bankIDCode := flags.String("bank-id-code", "", "bank ID code, e.g. GBDSC")
+	bic := flags.String("bic", "", "BIC")
+	accountNumber := flags.String("account-number", "", "account number")
+	iban := flags.String("iban", "", "IBAN")
+	classification := flags.String("classification", "", "account classification, Personal or Business")
+	var names stringList
+	flags.Var(&names, "name", "name of the account holder, repeat it for every line")
+	if err := c.parse(flags, args); err != nil {
+		return err
+	}
+
+	var reqModel *models.CreateRequest
+	if *file != "" {
+		var err error
+		if reqModel, err = readCreateRequest(c.stdin, *file); err != nil {
+			return err
+		}
+	} else {
+		if *organisationID == "" || *country == "" {
+			return c.usageError(flags, "-organisation-id and -country are required without -file")
+		}
+		if *id == "" {
+			*id = models.NewAccountID()
+		}
+
+		attributes := &models.AccountAttributes{
+			Country:       country,
+			BaseCurrency:  *baseCurrency,
+			BankID:        *bankID,
+			BankIDCode:    *bankIDCode,
+			Bic:           *bic,
+			AccountNumber: *accountNumber,
+			Iban:          *iban,
+			Name:          names,
+		}
+		if *classification != "" {
+			attributes.AccountClassification = classification
+		}
+
+		reqModel = &models.CreateRequest{
+			Data: &models.AccountData{
+				ID:             *id,
+				OrganisationID: *organisationID,
+				Type:           "accounts",
+				Attributes:     attributes,
+			},
+		}
+	}
+
+	res, err := c.service().CreateAccount(reqModel)
+	if err != nil {
+		return err
+	}
+
+	return c.print(res.ResBody.Data)
+}
+
+// readCreateRequest decodes a create request from path, or from stdin when path is -.
+func readCreateRequest(stdin io.Reader, path string) (*models.CreateRequest, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var reqModel models.CreateRequest
+	if err := json.Unmarshal(content, &reqModel); err != nil {
+		return nil, fmt.Errorf("invalid create request %s: %w", path, err)
+	}
+
+	return &reqModel, nil
+}
+
+func runFetch(c *cli, args []string) error {
+	flags := c.newFlagSet("fetch")
+	id := flags.String("id", "", "account ID")
+	if err := c.parse(flags, args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return c.usageError(flags, "-id is required")
+	}
+
+	res, err := c.service().FetchAccount(&models.FetchRequest{AccountId: *id})
+	if err != nil {
+		return err
+	}
+
+	return c.print(res.ResBody.Data)
+}
+
+func runDelete(c *cli, args []string) error {
+	flags := c.newFlagSet("delete")
+	id := flags.String("id", "", "account ID")
+	version := flags.Int("version", 0, "current version of the account")
+	if err := c.parse(flags, args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return c.usageError(flags, "-id is required")
+	}
+
+	if _, err := c.service().DeleteAccount(&models.DeleteRequest{AccountId: *id, Version: *version}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "account %s deleted\n", *id)
+	return nil
+}
+
+func runList(c *cli, args []string) error {
+	flags := c.newFlagSet("list")
+	pageNumber := flags.Int("page", 0, "page number")
+	pageSize := flags.Int("size", 100, "page size")
+	all := flags.Bool("all", false, "list every page, starting at -page")
+	filter := &models.ListFilter{}
+	flags.StringVar(&filter.BankID, "bank-id", "", "filter by bank ID")
+	flags.StringVar(&filter.BankIDCode, "bank-id-code", "", "filter by bank ID code")
+	flags.StringVar(&filter.AccountNumber, "account-number", "", "filter by account number")
+	flags.StringVar(&filter.Iban, "iban", "", "filter by IBAN")
+	flags.StringVar(&filter.Country, "country", "", "filter by country")
+	flags.StringVar(&filter.CustomerID, "customer-id", "", "filter by customer ID")
+	if err := c.parse(flags, args); err != nil {
+		return err
+	}
+
+	reqModel := &models.ListRequest{PageNumber: *pageNumber, PageSize: *pageSize, Filter: filter}
+	service := c.service()
+
+	if !*all {
+		res, err := service.ListAccounts(reqModel)
+		if err != nil {
+			return err
+		}
+		return c.print(res.ResBody.Data)
+	}
+
+	accounts := []models.ResponseData{}
+	it := api_client.NewAccountIterator(context.Background(), service, reqModel)
+	for it.Next() {
+		accounts = append(accounts, *it.Account())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	return c.print(accounts)
+}
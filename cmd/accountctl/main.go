@@ -0,0 +1,221 @@
+// Command accountctl creates, fetches, deletes and lists accounts of the account API, so they can be managed
+// without hand-crafting HTTP requests:
+//
+//	accountctl fetch -host accountapi -id ad27e265-9605-4b4b-a0e5-3003ea9cc4dc -output yaml
+//	ACCOUNTCTL_HOST=accountapi accountctl list -country GB -all
+//
+// Run accountctl help for every command and flag.
+package main
+
+import (
+	"accountapi-lib-form3/pkg/api_client"
+	"accountapi-lib-form3/pkg/configuration"
+	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/logging"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+)
+
+// Exit codes, they are derived from the kind of the AccountError returned by the account API.
+const (
+	exitOK           = 0
+	exitFailure      = 1
+	exitUsage        = 2
+	exitValidation   = 3
+	exitNotFound     = 4
+	exitConflict     = 5
+	exitUnauthorized = 6
+	exitUnavailable  = 7
+)
+
+const (
+	envHost       = "ACCOUNTCTL_HOST"
+	envPort       = "ACCOUNTCTL_PORT"
+	envAPIVersion = "ACCOUNTCTL_API_VERSION"
+	envVerbose    = "ACCOUNTCTL_VERBOSE"
+	envOutput     = "ACCOUNTCTL_OUTPUT"
+)
+
+const usage = `Usage: accountctl <command> [flags]
+
+Commands:
+  create  create an account from -file, a JSON create request, or from flags
+  fetch   fetch the account identified by -id
+  delete  delete the account identified by -id and -version
+  list    list a page of accounts, or every account with -all
+
+Every command accepts -host, -port, -api-version, -verbose and -output (table, json or yaml), which default to
+the environment variables ACCOUNTCTL_HOST, ACCOUNTCTL_PORT, ACCOUNTCTL_API_VERSION, ACCOUNTCTL_VERBOSE and
+ACCOUNTCTL_OUTPUT. Run accountctl <command> -h for the flags of a command.
+
+Exit codes:
+  0  success
+  1  unexpected failure
+  2  invalid usage
+  3  invalid request
+  4  account not found
+  5  conflict, e.g. duplicate account or wrong version
+  6  unauthorized
+  7  account API unavailable, e.g. timeout, rate limited or server error
+`
+
+// errUsage is returned when the command line is invalid, the reason has already been written.
+var errUsage = errors.New("invalid usage")
+
+// environment looks up an environment variable, it is os.Getenv outside tests.
+type environment func(key string) string
+
+// command runs a subcommand, its flags have been parsed from the arguments following its name.
+type command func(cli *cli, args []string) error
+
+var commands = map[string]command{
+	"create": runCreate,
+	"fetch":  runFetch,
+	"delete": runDelete,
+	"list":   runList,
+}
+
+// cli holds the streams and the common options shared by every command.
+type cli struct {
+	getenv     environment
+	stdin      io.Reader
+	stdout     io.Writer
+	stderr     io.Writer
+	host       string
+	port       string
+	apiVersion string
+	verbose    bool
+	output     string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, getenv environment, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "accountctl: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	c := &cli{getenv: getenv, stdin: stdin, stdout: stdout, stderr: stderr}
+	if err := cmd(c, args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "accountctl: %v\n", err)
+		}
+		return exitCode(err)
+	}
+
+	return exitOK
+}
+
+// newFlagSet creates the flags of a command, including the common options, which default to their
+// environment variable.
+func (c *cli) newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("accountctl "+name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+
+	verbose, _ := strconv.ParseBool(c.getenv(envVerbose))
+	flags.StringVar(&c.host, "host", c.env(envHost, "localhost"), "host of the account API, "+envHost)
+	flags.StringVar(&c.port, "port", c.env(envPort, "80"), "port of the account API, "+envPort)
+	flags.StringVar(&c.apiVersion, "api-version", c.env(envAPIVersion, "v1"), "version of the account API, "+envAPIVersion)
+	flags.BoolVar(&c.verbose, "verbose", verbose, "log requests and responses to stderr, "+envVerbose)
+	flags.StringVar(&c.output, "output", c.env(envOutput, formatTable), "output format: table, json or yaml, "+envOutput)
+
+	return flags
+}
+
+// parse parses the flags of a command, positional arguments are not accepted.
+func (c *cli) parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+
+	if flags.NArg() > 0 {
+		return c.usageError(flags, "unexpected argument %q", flags.Arg(0))
+	}
+
+	if _, ok := printers[c.output]; !ok {
+		return c.usageError(flags, "unknown output format %q", c.output)
+	}
+
+	return nil
+}
+
+// usageError writes the reason of an invalid command line along with the flags of the command.
+func (c *cli) usageError(flags *flag.FlagSet, format string, args ...interface{}) error {
+	fmt.Fprintf(c.stderr, "accountctl: "+format+"\n", args...)
+	flags.Usage()
+
+	return errUsage
+}
+
+func (c *cli) env(key string, fallback string) string {
+	if value := c.getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// service builds an AccountService from the common options, verbose logs are written to stderr, so they
+// do not mix with the output.
+func (c *cli) service() api_client.AccountManagement {
+	builder := configuration.NewDefaultConfigBuilder().
+		WithHost(c.host).
+		WithPort(c.port).
+		WithAPIVersion(c.apiVersion)
+
+	if c.verbose {
+		builder = builder.
+			WithLogger(logging.NewStdLogger(log.New(c.stderr, "", log.LstdFlags), logging.LevelDebug)).
+			Verbose()
+	}
+
+	config := builder.Build()
+	return api_client.NewAccountService(&config)
+}
+
+// exitCode maps the kind of err to an exit code.
+func exitCode(err error) int {
+	if errors.Is(err, errUsage) {
+		return exitUsage
+	}
+
+	switch error_handling.KindOf(err) {
+	case error_handling.KindValidation:
+		return exitValidation
+	case error_handling.KindNotFound:
+		return exitNotFound
+	case error_handling.KindConflict:
+		return exitConflict
+	case error_handling.KindUnauthorized:
+		return exitUnauthorized
+	case error_handling.KindTransport, error_handling.KindCanceled, error_handling.KindDeadlineExceeded,
+		error_handling.KindRateLimited, error_handling.KindServer, error_handling.KindCircuitOpen:
+		return exitUnavailable
+	default:
+		return exitFailure
+	}
+}
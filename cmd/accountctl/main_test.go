@@ -0,0 +1,123 @@
+package main
+
+import (
+	"accountapi-lib-form3/pkg/accountapitest"
+	"accountapi-lib-form3/pkg/error_handling"
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+const accountId = "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"
+
+// getServerEnv starts a fake account API and returns the environment variables pointing out to it.
+func getServerEnv(t *testing.T) (*accountapitest.Server, environment) {
+	server := accountapitest.NewServer()
+	t.Cleanup(server.Close)
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	env := map[string]string{envHost: host, envPort: port}
+
+	return server, func(key string) string { return env[key] }
+}
+
+func runCommand(env environment, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, env, strings.NewReader(stdin), &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_ShouldCreateFetchListAndDelete(t *testing.T) {
+	_, env := getServerEnv(t)
+
+	code, stdout, stderr := runCommand(env, "", "create", "-id", accountId, "-organisation-id", accountId,
+		"-country", "GB", "-bank-id", "400302", "-bic", "NWBKGB42", "-name", "Samantha", "-name", "Holder")
+	if code != exitOK || !strings.Contains(stdout, accountId) || !strings.Contains(stdout, "Samantha Holder") {
+		t.Fatalf("create wanted: table with the account\n got: %d %s %s", code, stdout, stderr)
+	}
+
+	code, stdout, _ = runCommand(env, "", "fetch", "-id", accountId, "-output", "json")
+	if code != exitOK || !strings.Contains(stdout, `"id": "`+accountId+`"`) {
+		t.Fatalf("fetch wanted: JSON account\n got: %d %s", code, stdout)
+	}
+
+	code, stdout, _ = runCommand(env, "", "list", "-all", "-country", "GB", "-output", "yaml")
+	if code != exitOK || !strings.Contains(stdout, "- attributes:\n    bank_id: \"400302\"\n") || !strings.Contains(stdout, "  id: "+accountId+"\n") {
+		t.Fatalf("list wanted: YAML accounts\n got: %d %s", code, stdout)
+	}
+
+	code, _, stderr = runCommand(env, "", "delete", "-id", accountId, "-version", "0")
+	if code != exitOK || !strings.Contains(stderr, "deleted") {
+		t.Fatalf("delete wanted: deleted\n got: %d %s", code, stderr)
+	}
+
+	code, _, stderr = runCommand(env, "", "fetch", "-id", accountId)
+	if code != exitNotFound {
+		t.Errorf("fetch after delete wanted: %d\n got: %d %s", exitNotFound, code, stderr)
+	}
+}
+
+func TestRun_ShouldCreateFromStdin(t *testing.T) {
+	server, env := getServerEnv(t)
+	request := `{"data":{"id":"` + accountId + `","organisation_id":"` + accountId + `","type":"accounts","attributes":{"country":"GB","name":["Samantha Holder"]}}}`
+
+	code, _, stderr := runCommand(env, request, "create", "-file", "-")
+	if code != exitOK || len(server.Accounts()) != 1 {
+		t.Fatalf("wanted: account created\n got: %d %s", code, stderr)
+	}
+
+	code, _, _ = runCommand(env, request, "create", "-file", "-")
+	if code != exitConflict {
+		t.Errorf("duplicate wanted: %d\n got: %d", exitConflict, code)
+	}
+}
+
+func TestRun_ShouldRejectInvalidUsage(t *testing.T) {
+	_, env := getServerEnv(t)
+
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"fetch"},
+		{"fetch", "-id", accountId, "-output", "xml"},
+		{"fetch", "-unknown"},
+		{"create", "-country", "GB"},
+	} {
+		if code, _, _ := runCommand(env, "", args...); code != exitUsage {
+			t.Errorf("%v wanted: %d\n got: %d", args, exitUsage, code)
+		}
+	}
+}
+
+func TestExitCode_ShouldMapErrorKinds(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{error_handling.NewStatusError("Fetch", 400, ""), exitValidation},
+		{error_handling.NewStatusError("Fetch", 403, ""), exitUnauthorized},
+		{error_handling.NewStatusError("Fetch", 503, ""), exitUnavailable},
+		{error_handling.NewInternalError("Fetch", error_handling.KindDeadlineExceeded, 8, "", nil), exitUnavailable},
+		{error_handling.NewInternalError("Fetch", error_handling.KindDecode, 6, "", nil), exitFailure},
+		{errors.New("other"), exitFailure},
+	}
+
+	for _, v := range cases {
+		if got := exitCode(v.err); got != v.want {
+			t.Errorf("%v wanted: %d\n got: %d", v.err, v.want, got)
+		}
+	}
+}
+
+func TestPrintYAML_ShouldQuoteAmbiguousStrings(t *testing.T) {
+	var out strings.Builder
+	_ = printYAML(&out, map[string]interface{}{"a": "true", "b": "GB", "c": []string{"x: y"}, "d": nil})
+
+	want := "a: \"true\"\nb: GB\nc:\n  - \"x: y\"\nd: null\n"
+	if got := out.String(); got != want {
+		t.Errorf("wanted:\n%s\n got:\n%s", want, got)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"accountapi-lib-form3/pkg/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// printer writes an account, *models.ResponseData, or a list of accounts, []models.ResponseData.
+type printer func(out io.Writer, value interface{}) error
+
+var printers = map[string]printer{
+	formatTable: printTable,
+	formatJSON:  printJSON,
+	formatYAML:  printYAML,
+}
+
+func (c *cli) print(value interface{}) error {
+	return printers[c.output](c.stdout, value)
+}
+
+func printTable(out io.Writer, value interface{}) error {
+	var accounts []models.ResponseData
+	switch v := value.(type) {
+	case *models.ResponseData:
+		if v != nil {
+			accounts = []models.ResponseData{*v}
+		}
+	case []models.ResponseData:
+		accounts = v
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tORGANISATION ID\tCOUNTRY\tBANK ID\tBIC\tACCOUNT NUMBER\tNAME\tVERSION")
+	for _, account := range accounts {
+		attributes := models.AccountAttributes{}
+		if account.Attributes != nil {
+			attributes = *account.Attributes
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", account.ID, account.OrganisationID, stringOf(attributes.Country),
+			attributes.BankID, attributes.Bic, attributes.AccountNumber, strings.Join(attributes.Name, " "), versionOf(account.Version))
+	}
+
+	return w.Flush()
+}
+
+func stringOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func versionOf(version *int64) string {
+	if version == nil {
+		return ""
+	}
+
+	return strconv.FormatInt(*version, 10)
+}
+
+func printJSON(out io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// printYAML writes value as YAML, going through its JSON representation so field names are the ones of the
+// account API. Keys are sorted.
+func printYAML(out io.Writer, value interface{}) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(content, &generic); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	writeYAML(&b, generic, 0)
+	_, err = io.WriteString(out, b.String())
+
+	return err
+}
+
+func writeYAML(b *strings.Builder, value interface{}, indent int) {
+	prefix := strings.Repeat("  ", indent)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			b.WriteString(prefix + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if isScalar(v[key]) {
+				b.WriteString(prefix + key + ": " + yamlScalar(v[key]) + "\n")
+				continue
+			}
+			b.WriteString(prefix + key + ":\n")
+			writeYAML(b, v[key], indent+1)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString(prefix + "[]\n")
+			return
+		}
+		for _, item := range v {
+			if isScalar(item) {
+				b.WriteString(prefix + "- " + yamlScalar(item) + "\n")
+				continue
+			}
+			// the first line of a nested item goes after the dash, the rest keep the indentation
+			var nested strings.Builder
+			writeYAML(&nested, item, indent+1)
+			b.WriteString(prefix + "- " + strings.TrimPrefix(nested.String(), prefix+"  "))
+		}
+	default:
+		b.WriteString(prefix + yamlScalar(v) + "\n")
+	}
+}
+
+func isScalar(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	default:
+		return true
+	}
+}
+
+// yamlScalar formats a JSON scalar, strings are quoted when they would be read as another type.
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	case string:
+		if needsQuotes(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func needsQuotes(value string) bool {
+	if value == "" || strings.TrimSpace(value) != value || strings.ContainsAny(value, ":#{}[],&*!|>'\"%@`\n") {
+		return true
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~", "-":
+		return true
+	}
+
+	return strings.HasPrefix(value, "- ")
+}
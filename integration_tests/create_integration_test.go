@@ -22,9 +22,11 @@ func Test_CreateAccount(t *testing.T) {
 
 	id := "3b9ef55d-fe5e-434b-9f60-d5a0f9758887"
 
+	// wrong IDs have to reach the account API, so its own validation is checked
 	config := configuration.NewDefaultConfigBuilder().
 		WithPort("8080").
 		WithHost("accountapi").
+		WithoutRequestValidation().
 		Build()
 
 	subject := api_client.NewAccountService(&config)
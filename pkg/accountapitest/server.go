@@ -0,0 +1,416 @@
+// Package accountapitest provides an in-process fake of the account API to test components that use
+// this library without running the real account API.
+package accountapitest
+
+import (
+	"accountapi-lib-form3/pkg/configuration"
+	"accountapi-lib-form3/pkg/models"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	accountsPath        = "/organisation/accounts"
+	apiVersion          = "v1"
+	jsonAPIMediaType    = "application/vnd.api+json"
+	contentTypeHeader   = "Content-Type"
+	defaultPageSize     = 100
+	msgInvalidID        = "id is not a valid uuid"
+	msgInvalidBodyID    = "id in body must be of type uuid"
+	msgInvalidOrgID     = "organisation_id in body must be of type uuid"
+	msgInvalidBody      = "invalid request body"
+	msgMissingCountry   = "country in body is required"
+	msgDuplicateAccount = "Account cannot be created as it violates a duplicate constraint"
+	msgInvalidVersion   = "invalid version"
+	msgInvalidVersionNb = "invalid version number"
+	msgRecordNotFound   = "record %s does not exist"
+	msgInvalidPage      = "invalid page parameter"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Server is a httptest.Server that implements create, fetch, delete, list and update of accounts against
+// an in-memory store. It reproduces the behaviour of the account API: duplicate IDs and wrong versions
+// are answered with 409, unknown IDs with 404 and invalid UUIDs with 400.
+type Server struct {
+	*httptest.Server
+	mu       sync.Mutex
+	accounts map[string]*models.ResponseData
+	order    []string
+	now      func() time.Time
+}
+
+// NewServer starts a fake account API, it has to be closed by invoking Close.
+func NewServer() *Server {
+	s := &Server{
+		accounts: map[string]*models.ResponseData{},
+		now:      time.Now,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// ConfigBuilder returns a builder pointing out to the fake account API, so it can be customised
+// further before invoking Build.
+func (s *Server) ConfigBuilder() configuration.ConfigBuilder {
+	host, port, _ := net.SplitHostPort(s.Listener.Addr().String())
+
+	return configuration.NewDefaultConfigBuilder().
+		WithHost(host).
+		WithPort(port).
+		WithAPIVersion(apiVersion)
+}
+
+// Seed stores accounts as they were created through the API, it is useful to prepare a test scenario.
+func (s *Server) Seed(accounts ...models.ResponseData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range accounts {
+		account := accounts[i]
+		if account.Version == nil {
+			version := int64(0)
+			account.Version = &version
+		}
+		if _, ok := s.accounts[account.ID]; !ok {
+			s.order = append(s.order, account.ID)
+		}
+		s.accounts[account.ID] = &account
+	}
+}
+
+// Accounts returns a copy of the stored accounts in creation order.
+func (s *Server) Accounts() []models.ResponseData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts := make([]models.ResponseData, 0, len(s.order))
+	for _, id := range s.order {
+		accounts = append(accounts, *s.accounts[id])
+	}
+
+	return accounts
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	idx := strings.Index(r.URL.Path, accountsPath)
+	if idx < 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path[idx+len(accountsPath):], "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		s.create(w, r)
+	case id == "" && r.Method == http.MethodGet:
+		s.list(w, r)
+	case id != "" && r.Method == http.MethodGet:
+		s.fetch(w, id)
+	case id != "" && r.Method == http.MethodDelete:
+		s.delete(w, r, id)
+	case id != "" && r.Method == http.MethodPatch:
+		s.update(w, r, id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Data == nil {
+		writeError(w, http.StatusBadRequest, msgInvalidBody)
+		return
+	}
+
+	data := req.Data
+	switch {
+	case !uuidPattern.MatchString(data.ID):
+		writeError(w, http.StatusBadRequest, msgInvalidBodyID)
+		return
+	case !uuidPattern.MatchString(data.OrganisationID):
+		writeError(w, http.StatusBadRequest, msgInvalidOrgID)
+		return
+	case data.Attributes == nil || data.Attributes.Country == nil:
+		writeError(w, http.StatusBadRequest, msgMissingCountry)
+		return
+	}
+
+	if _, ok := s.accounts[data.ID]; ok {
+		writeError(w, http.StatusConflict, msgDuplicateAccount)
+		return
+	}
+
+	now := s.now().UTC()
+	version := int64(0)
+	account := &models.ResponseData{
+		Attributes:     data.Attributes,
+		CreateOn:       now,
+		ID:             data.ID,
+		ModifiedOn:     now,
+		OrganisationID: data.OrganisationID,
+		Type:           data.Type,
+		Version:        &version,
+	}
+	s.accounts[data.ID] = account
+	s.order = append(s.order, data.ID)
+
+	writeAccount(w, http.StatusCreated, account)
+}
+
+func (s *Server) fetch(w http.ResponseWriter, id string) {
+	if !uuidPattern.MatchString(id) {
+		writeError(w, http.StatusBadRequest, msgInvalidID)
+		return
+	}
+
+	account, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf(msgRecordNotFound, id))
+		return
+	}
+
+	writeAccount(w, http.StatusOK, account)
+}
+
+// delete answers 404 without body when the account does not exist, as the account API does.
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, id string) {
+	if !uuidPattern.MatchString(id) {
+		writeError(w, http.StatusBadRequest, msgInvalidID)
+		return
+	}
+
+	version, err := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, msgInvalidVersionNb)
+		return
+	}
+
+	account, ok := s.accounts[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if *account.Version != version {
+		writeError(w, http.StatusConflict, msgInvalidVersion)
+		return
+	}
+
+	delete(s.accounts, id)
+	for i, storedID := range s.order {
+		if storedID == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// update merges the attributes present in the body into the stored ones, only when the version in the
+// body is the current one.
+func (s *Server) update(w http.ResponseWriter, r *http.Request, id string) {
+	if !uuidPattern.MatchString(id) {
+		writeError(w, http.StatusBadRequest, msgInvalidID)
+		return
+	}
+
+	var req struct {
+		Data *struct {
+			Version    *int64          `json:"version"`
+			Attributes json.RawMessage `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Data == nil || req.Data.Version == nil {
+		writeError(w, http.StatusBadRequest, msgInvalidBody)
+		return
+	}
+
+	account, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf(msgRecordNotFound, id))
+		return
+	}
+
+	if *account.Version != *req.Data.Version {
+		writeError(w, http.StatusConflict, msgInvalidVersion)
+		return
+	}
+
+	attributes := models.AccountAttributes{}
+	if account.Attributes != nil {
+		attributes = *account.Attributes
+	}
+	if len(req.Data.Attributes) > 0 {
+		if err := json.Unmarshal(req.Data.Attributes, &attributes); err != nil {
+			writeError(w, http.StatusBadRequest, msgInvalidBody)
+			return
+		}
+	}
+
+	version := *account.Version + 1
+	updated := *account
+	updated.Attributes = &attributes
+	updated.Version = &version
+	updated.ModifiedOn = s.now().UTC()
+	s.accounts[id] = &updated
+
+	writeAccount(w, http.StatusOK, &updated)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	pageNumber, pageSize, ok := pageParams(query)
+	if !ok {
+		writeError(w, http.StatusBadRequest, msgInvalidPage)
+		return
+	}
+
+	matching := make([]models.ResponseData, 0, len(s.order))
+	for _, id := range s.order {
+		if account := s.accounts[id]; matchesFilters(account, query) {
+			matching = append(matching, *account)
+		}
+	}
+
+	start := pageNumber * pageSize
+	end := start + pageSize
+	if start > len(matching) {
+		start = len(matching)
+	}
+	if end > len(matching) {
+		end = len(matching)
+	}
+
+	lastPage := 0
+	if len(matching) > 0 {
+		lastPage = (len(matching) - 1) / pageSize
+	}
+
+	links := &models.Link{
+		Self:  pageLink(query, pageNumber, pageSize),
+		First: pageLink(query, 0, pageSize),
+		Last:  pageLink(query, lastPage, pageSize),
+	}
+	if pageNumber > 0 {
+		links.Prev = pageLink(query, pageNumber-1, pageSize)
+	}
+	if pageNumber < lastPage {
+		links.Next = pageLink(query, pageNumber+1, pageSize)
+	}
+
+	writeJSON(w, http.StatusOK, models.ListResponseObject{
+		Data:  matching[start:end],
+		Links: links,
+	})
+}
+
+func pageParams(query url.Values) (int, int, bool) {
+	pageNumber, pageSize := 0, defaultPageSize
+
+	if value := query.Get("page[number]"); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			return 0, 0, false
+		}
+		pageNumber = number
+	}
+
+	if value := query.Get("page[size]"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 {
+			return 0, 0, false
+		}
+		pageSize = size
+	}
+
+	return pageNumber, pageSize, true
+}
+
+// matchesFilters evaluates filter[...] query parameters, a parameter may hold several values separated
+// by commas. customer_id is not part of the models, so it is ignored.
+func matchesFilters(account *models.ResponseData, query url.Values) bool {
+	attributes := account.Attributes
+	if attributes == nil {
+		attributes = &models.AccountAttributes{}
+	}
+
+	country := ""
+	if attributes.Country != nil {
+		country = *attributes.Country
+	}
+
+	values := map[string]string{
+		"bank_id":        attributes.BankID,
+		"bank_id_code":   attributes.BankIDCode,
+		"account_number": attributes.AccountNumber,
+		"iban":           attributes.Iban,
+		"country":        country,
+	}
+
+	for name, value := range values {
+		filter := query.Get("filter[" + name + "]")
+		if filter == "" {
+			continue
+		}
+
+		found := false
+		for _, candidate := range strings.Split(filter, ",") {
+			if candidate == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func pageLink(query url.Values, pageNumber int, pageSize int) string {
+	linkQuery := url.Values{}
+	for name, values := range query {
+		linkQuery[name] = values
+	}
+	linkQuery.Set("page[number]", strconv.Itoa(pageNumber))
+	linkQuery.Set("page[size]", strconv.Itoa(pageSize))
+
+	return "/" + apiVersion + accountsPath + "?" + linkQuery.Encode()
+}
+
+func writeAccount(w http.ResponseWriter, statusCode int, account *models.ResponseData) {
+	writeJSON(w, statusCode, models.ResponseObject{
+		Data: account,
+		Links: &models.Link{
+			Self: "/" + apiVersion + accountsPath + "/" + account.ID,
+		},
+	})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, models.ResponseError{ErrorMessage: message})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set(contentTypeHeader, jsonAPIMediaType)
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,69 @@
+package accountapitest
+
+import (
+	"accountapi-lib-form3/pkg/models"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func getList(t *testing.T, server *Server, query string) (int, models.ListResponseObject) {
+	res, err := http.Get(server.URL + "/v1/organisation/accounts" + query)
+	if err != nil {
+		t.Fatalf("failed listing accounts: %v", err)
+	}
+	defer res.Body.Close()
+
+	var out models.ListResponseObject
+	_ = json.NewDecoder(res.Body).Decode(&out)
+
+	return res.StatusCode, out
+}
+
+func TestServer_ShouldPaginateAndFilter(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	gb, de := "GB", "DE"
+	server.Seed(
+		models.ResponseData{ID: "ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6", Attributes: &models.AccountAttributes{Country: &gb}},
+		models.ResponseData{ID: "3b9ef55d-fe5e-434b-9f60-d5a0f9758887", Attributes: &models.AccountAttributes{Country: &de}},
+		models.ResponseData{ID: "e6f3eed0-3f37-416a-9cfc-187c2caadb69", Attributes: &models.AccountAttributes{Country: &gb}},
+	)
+
+	statusCode, got := getList(t, server, "?page[number]=0&page[size]=1&filter[country]=GB")
+	if statusCode != 200 || len(got.Data) != 1 || got.Data[0].ID != "ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6" {
+		t.Fatalf("first page wanted: first GB account\n got: %d %v", statusCode, got.Data)
+	}
+	if got.Links.Next == "" || got.Links.Prev != "" {
+		t.Errorf("first page links wanted: next without prev\n got: %#v", got.Links)
+	}
+
+	_, got = getList(t, server, "?page[number]=1&page[size]=1&filter[country]=GB")
+	if len(got.Data) != 1 || got.Data[0].ID != "e6f3eed0-3f37-416a-9cfc-187c2caadb69" {
+		t.Fatalf("second page wanted: second GB account\n got: %v", got.Data)
+	}
+	if got.Links.Next != "" || got.Links.Prev == "" {
+		t.Errorf("last page links wanted: prev without next\n got: %#v", got.Links)
+	}
+
+	statusCode, _ = getList(t, server, "?page[size]=0")
+	if statusCode != 400 {
+		t.Errorf("invalid page size wanted: 400\n got: %d", statusCode)
+	}
+}
+
+func TestServer_ShouldAnswerUnknownRoutesWithNotFound(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/v1/other")
+	if err != nil {
+		t.Fatalf("failed invoking server: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 404 {
+		t.Errorf("wanted: 404\n got: %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,163 @@
+package api_client
+
+import (
+	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/models"
+	"context"
+	"errors"
+	"sync"
+)
+
+const defaultBatchWorkers = 4
+
+// BatchMode defines what a batch does once one of its items fails.
+type BatchMode int
+
+const (
+	// ContinueOnError sends every item regardless of the failures of the others.
+	ContinueOnError BatchMode = iota
+	// FailFast stops sending items after the first failure and cancels the items in flight, their result
+	// wraps error_handling.ErrBatchAborted.
+	FailFast
+)
+
+// BatchOptions configures a batch, the zero value sends every item with 4 workers.
+type BatchOptions struct {
+	// Workers is the number of items sent at the same time, 4 when it is lower than 1. The rate limit and
+	// concurrency cap of the configuration still apply.
+	Workers int
+	Mode    BatchMode
+}
+
+// CreateResult is the result of an item of CreateAccounts, either Response or Err is set.
+type CreateResult struct {
+	Response *models.CreateResponse
+	Err      error
+}
+
+// FetchResult is the result of an item of FetchAccounts, either Response or Err is set.
+type FetchResult struct {
+	Response *models.FetchResponse
+	Err      error
+}
+
+// DeleteResult is the result of an item of DeleteAccounts, either Response or Err is set.
+type DeleteResult struct {
+	Response *models.DeleteResponse
+	Err      error
+}
+
+// CreateAccounts creates every account of reqModels with a pool of workers, results are in the same order as
+// reqModels. When ctx is done, the items that were not sent fail with code 7 or 8.
+func (a *AccountService) CreateAccounts(ctx context.Context, reqModels []*models.CreateRequest, options BatchOptions) []CreateResult {
+	results := make([]CreateResult, len(reqModels))
+
+	errs := runBatch(ctx, createOperation, len(reqModels), options, func(ctx context.Context, i int) error {
+		var err error
+		results[i].Response, err = a.CreateAccountWithContext(ctx, reqModels[i])
+		return err
+	})
+	for i, err := range errs {
+		results[i].Err = err
+	}
+
+	return results
+}
+
+// FetchAccounts fetches every account of reqModels with a pool of workers, results are in the same order as
+// reqModels. When ctx is done, the items that were not sent fail with code 7 or 8.
+func (a *AccountService) FetchAccounts(ctx context.Context, reqModels []*models.FetchRequest, options BatchOptions) []FetchResult {
+	results := make([]FetchResult, len(reqModels))
+
+	errs := runBatch(ctx, fetchOperation, len(reqModels), options, func(ctx context.Context, i int) error {
+		var err error
+		results[i].Response, err = a.FetchAccountWithContext(ctx, reqModels[i])
+		return err
+	})
+	for i, err := range errs {
+		results[i].Err = err
+	}
+
+	return results
+}
+
+// DeleteAccounts deletes every account of reqModels with a pool of workers, results are in the same order as
+// reqModels. When ctx is done, the items that were not sent fail with code 7 or 8.
+func (a *AccountService) DeleteAccounts(ctx context.Context, reqModels []*models.DeleteRequest, options BatchOptions) []DeleteResult {
+	results := make([]DeleteResult, len(reqModels))
+
+	errs := runBatch(ctx, deleteOperation, len(reqModels), options, func(ctx context.Context, i int) error {
+		var err error
+		results[i].Response, err = a.DeleteAccountWithContext(ctx, reqModels[i])
+		return err
+	})
+	for i, err := range errs {
+		results[i].Err = err
+	}
+
+	return results
+}
+
+// runBatch invokes send for every index from 0 to size with a pool of workers and returns the error of every
+// index. Indexes that were not sent get the error of ctx or, when the batch failed fast, an aborted error, which
+// also replaces the cancellation of the items in flight at that moment.
+func runBatch(ctx context.Context, operation string, size int, options BatchOptions, send func(ctx context.Context, i int) error) []error {
+	errs := make([]error, size)
+	sent := make([]bool, size)
+
+	workers := options.Workers
+	if workers < 1 {
+		workers = defaultBatchWorkers
+	}
+	if workers > size {
+		workers = size
+	}
+
+	batchCtx, abort := context.WithCancel(ctx)
+	defer abort()
+
+	var abortOnce sync.Once
+	failed := -1
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				sent[i] = true
+				errs[i] = send(batchCtx, i)
+				if errs[i] != nil && options.Mode == FailFast {
+					abortOnce.Do(func() {
+						failed = i
+						abort()
+					})
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < size; i++ {
+		select {
+		case indexes <- i:
+		case <-batchCtx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i := range errs {
+		switch {
+		case i == failed:
+		case !sent[i] && ctx.Err() != nil:
+			errs[i] = newInvokingBackendError(ctx, operation, ctx.Err())
+		case !sent[i] || (failed >= 0 && ctx.Err() == nil && errors.Is(errs[i], error_handling.ErrCanceled)):
+			errs[i] = newInternalError(operation, codeBatchAborted, msgBatchAborted+"a previous item failed", error_handling.ErrBatchAborted)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,114 @@
+package api_client
+
+import (
+	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/models"
+	"context"
+	"errors"
+	"testing"
+)
+
+var batchAccountIds = []string{
+	"0d27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+	"1e5a3f04-2c8e-4a71-9a2f-6b3c2d1e0f47",
+	"2f6b4a15-3d9f-4b82-8b3a-7c4d3e2f1a58",
+	"3a7c5b26-4e0a-4c93-9c4b-8d5e4f3a2b69",
+	"4b8d6c37-5f1b-4da4-8d5c-9e6f5a4b3c7a",
+}
+
+func TestAccountService_ShouldCreateFetchAndDeleteInBatchesPreservingOrder(t *testing.T) {
+	_, subject := getServerSubject(t)
+
+	createRequests := make([]*models.CreateRequest, len(batchAccountIds))
+	fetchRequests := make([]*models.FetchRequest, len(batchAccountIds))
+	deleteRequests := make([]*models.DeleteRequest, len(batchAccountIds))
+	for i, id := range batchAccountIds {
+		createRequests[i] = getCreateRequest(id)
+		fetchRequests[i] = &models.FetchRequest{AccountId: id}
+		deleteRequests[i] = &models.DeleteRequest{AccountId: id}
+	}
+
+	for i, result := range subject.CreateAccounts(context.Background(), createRequests, BatchOptions{Workers: 3}) {
+		if result.Err != nil || result.Response.ResBody.Data.ID != batchAccountIds[i] {
+			t.Fatalf("create %d wanted: %s\n got: %v %v", i, batchAccountIds[i], result.Response, result.Err)
+		}
+	}
+
+	for i, result := range subject.FetchAccounts(context.Background(), fetchRequests, BatchOptions{Workers: 3}) {
+		if result.Err != nil || result.Response.ResBody.Data.ID != batchAccountIds[i] {
+			t.Fatalf("fetch %d wanted: %s\n got: %v %v", i, batchAccountIds[i], result.Response, result.Err)
+		}
+	}
+
+	for i, result := range subject.DeleteAccounts(context.Background(), deleteRequests, BatchOptions{}) {
+		if result.Err != nil || result.Response.StatusCode != 204 {
+			t.Fatalf("delete %d wanted: 204\n got: %v %v", i, result.Response, result.Err)
+		}
+	}
+}
+
+func TestAccountService_ShouldContinueBatchOnError(t *testing.T) {
+	server, subject := getServerSubject(t)
+	server.Seed(models.ResponseData{ID: batchAccountIds[0]}, models.ResponseData{ID: batchAccountIds[2]})
+
+	results := subject.FetchAccounts(context.Background(), []*models.FetchRequest{
+		{AccountId: batchAccountIds[0]},
+		{AccountId: batchAccountIds[1]},
+		{AccountId: batchAccountIds[2]},
+	}, BatchOptions{Workers: 2, Mode: ContinueOnError})
+
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("wanted: existing accounts fetched\n got: %v, %v", results[0].Err, results[2].Err)
+	}
+	if !errors.Is(results[1].Err, error_handling.ErrNotFound) || results[1].Response != nil {
+		t.Errorf("wanted: %v\n got: %v", error_handling.ErrNotFound, results[1].Err)
+	}
+}
+
+func TestAccountService_ShouldAbortBatchOnFirstErrorWhenFailingFast(t *testing.T) {
+	server, subject := getServerSubject(t)
+	server.Seed(models.ResponseData{ID: batchAccountIds[1]})
+
+	results := subject.FetchAccounts(context.Background(), []*models.FetchRequest{
+		{AccountId: batchAccountIds[0]},
+		{AccountId: batchAccountIds[1]},
+		{AccountId: batchAccountIds[2]},
+	}, BatchOptions{Workers: 1, Mode: FailFast})
+
+	if !errors.Is(results[0].Err, error_handling.ErrNotFound) {
+		t.Errorf("first wanted: %v\n got: %v", error_handling.ErrNotFound, results[0].Err)
+	}
+	for _, result := range results[1:] {
+		if !errors.Is(result.Err, error_handling.ErrBatchAborted) || !errors.Is(result.Err, error_handling.ErrCanceled) {
+			t.Errorf("wanted: %v\n got: %v", error_handling.ErrBatchAborted, result.Err)
+		}
+	}
+}
+
+func TestAccountService_ShouldNotSendBatchWhenContextIsDone(t *testing.T) {
+	server, subject := getServerSubject(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := subject.CreateAccounts(ctx, []*models.CreateRequest{
+		getCreateRequest(batchAccountIds[0]),
+		getCreateRequest(batchAccountIds[1]),
+	}, BatchOptions{})
+
+	for i, result := range results {
+		if !errors.Is(result.Err, error_handling.ErrCanceled) || errors.Is(result.Err, error_handling.ErrBatchAborted) {
+			t.Errorf("%d wanted: %v\n got: %v", i, error_handling.ErrCanceled, result.Err)
+		}
+	}
+	if got := server.Accounts(); len(got) != 0 {
+		t.Errorf("wanted: no account created\n got: %v", got)
+	}
+}
+
+func TestAccountService_ShouldReturnNoResultsForEmptyBatch(t *testing.T) {
+	_, subject := getServerSubject(t)
+
+	if got := subject.DeleteAccounts(context.Background(), nil, BatchOptions{}); len(got) != 0 {
+		t.Errorf("wanted: no results\n got: %v", got)
+	}
+}
@@ -0,0 +1,116 @@
+package api_client
+
+import (
+	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/models"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// EnsureAccount creates the account of reqModel, unless it already exists. When the creation fails with a
+// conflict, e.g. because a previous attempt timed out after the account API created it, the account with the
+// same ID is fetched and compared with reqModel. The existing account is returned, with the status code of the
+// fetch, when every field set in reqModel has the same value, otherwise an error of kind conflict caused by an
+// error_handling.AccountMismatchError listing the differing fields is returned. Fields omitted in reqModel are
+// not compared, as the account API may fill them in. When there is no account with the same ID, the conflict
+// comes from another constraint and it is returned as it is.
+func EnsureAccount(ctx context.Context, management AccountManagement, reqModel *models.CreateRequest) (*models.CreateResponse, error) {
+	created, err := management.CreateAccountWithContext(ctx, reqModel)
+	if !errors.Is(err, error_handling.ErrConflict) {
+		return created, err
+	}
+
+	if reqModel.Data == nil || reqModel.Data.ID == "" {
+		return nil, err
+	}
+
+	fetched, fetchErr := management.FetchAccountWithContext(ctx, &models.FetchRequest{AccountId: reqModel.Data.ID})
+	if errors.Is(fetchErr, error_handling.ErrNotFound) {
+		// the conflict comes from another uniqueness constraint, not from an account with the same ID
+		return nil, err
+	}
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	if fetched.ResBody == nil || fetched.ResBody.Data == nil {
+		return nil, newInternalError(createOperation, codeMissingAccountData, msgMissingAccountData+"fetched account is empty", nil)
+	}
+
+	if differences := diffAccount(reqModel.Data, fetched.ResBody.Data); len(differences) > 0 {
+		mismatch := &error_handling.AccountMismatchError{AccountID: reqModel.Data.ID, Differences: differences}
+		return nil, newInternalError(createOperation, codeAccountMismatch, msgAccountMismatch+mismatch.Error(), mismatch)
+	}
+
+	return &models.CreateResponse{
+		ResBody:    fetched.ResBody,
+		StatusCode: fetched.StatusCode,
+	}, nil
+}
+
+// diffAccount compares the fields set in requested with existing, in declaration order.
+func diffAccount(requested *models.AccountData, existing *models.ResponseData) []error_handling.FieldDifference {
+	var differences []error_handling.FieldDifference
+
+	if requested.OrganisationID != "" && requested.OrganisationID != existing.OrganisationID {
+		differences = append(differences, error_handling.FieldDifference{
+			Field:     "organisation_id",
+			Requested: requested.OrganisationID,
+			Existing:  existing.OrganisationID,
+		})
+	}
+
+	if requested.Attributes == nil {
+		return differences
+	}
+
+	existingAttributes := &models.AccountAttributes{}
+	if existing.Attributes != nil {
+		existingAttributes = existing.Attributes
+	}
+
+	requestedValue := reflect.ValueOf(requested.Attributes).Elem()
+	existingValue := reflect.ValueOf(existingAttributes).Elem()
+	for i := 0; i < requestedValue.NumField(); i++ {
+		want, got := fieldValue(requestedValue.Field(i)), fieldValue(existingValue.Field(i))
+		if want == nil || reflect.DeepEqual(want, got) {
+			continue
+		}
+
+		differences = append(differences, error_handling.FieldDifference{
+			Field:     "attributes." + jsonName(requestedValue.Type().Field(i)),
+			Requested: want,
+			Existing:  got,
+		})
+	}
+
+	return differences
+}
+
+// fieldValue dereferences pointers and returns nil for unset fields, so an omitted field and an empty one
+// are considered equal.
+func fieldValue(value reflect.Value) interface{} {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if (value.IsZero() && value.Kind() != reflect.Bool) || (value.Kind() == reflect.Slice && value.Len() == 0) {
+		return nil
+	}
+
+	return value.Interface()
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
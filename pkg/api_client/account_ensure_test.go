@@ -0,0 +1,148 @@
+package api_client
+
+import (
+	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/models"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// existingManagementFake rejects the creation of an account with a conflict when existing is set, fetching an
+// account with another ID than existing fails with 404.
+type existingManagementFake struct {
+	AccountManagement
+	existing *models.ResponseData
+	fetches  int
+}
+
+func (e *existingManagementFake) CreateAccountWithContext(_ context.Context, reqModel *models.CreateRequest) (*models.CreateResponse, error) {
+	if e.existing != nil {
+		return nil, error_handling.NewStatusError(createOperation, http.StatusConflict, "Account cannot be created as it violates a duplicate constraint")
+	}
+
+	data := &models.ResponseData{ID: reqModel.Data.ID, Attributes: reqModel.Data.Attributes}
+	return &models.CreateResponse{ResBody: &models.ResponseObject{Data: data}, StatusCode: http.StatusCreated}, nil
+}
+
+func (e *existingManagementFake) FetchAccountWithContext(_ context.Context, reqModel *models.FetchRequest) (*models.FetchResponse, error) {
+	e.fetches++
+	if reqModel.AccountId != e.existing.ID {
+		return nil, error_handling.NewStatusError(fetchOperation, http.StatusNotFound, "")
+	}
+	return &models.FetchResponse{ResBody: &models.ResponseObject{Data: e.existing}, StatusCode: http.StatusOK}, nil
+}
+
+func getEnsureRequest() *models.CreateRequest {
+	country := "GB"
+	return &models.CreateRequest{
+		Data: &models.AccountData{
+			ID:             AccountId,
+			OrganisationID: AccountId,
+			Type:           accountType,
+			Attributes: &models.AccountAttributes{
+				Country: &country,
+				BankID:  "400302",
+				Name:    []string{"Samantha Holder"},
+			},
+		},
+	}
+}
+
+func TestEnsureAccount_ShouldCreateAccount(t *testing.T) {
+	fake := &existingManagementFake{}
+
+	got, err := EnsureAccount(context.Background(), fake, getEnsureRequest())
+
+	if err != nil || got.StatusCode != http.StatusCreated {
+		t.Fatalf("wanted: created account\n got: %v %v", got, err)
+	}
+	if fake.fetches != 0 {
+		t.Errorf("fetches wanted: 0\n fetches got: %d", fake.fetches)
+	}
+}
+
+func TestEnsureAccount_ShouldReturnIdenticalExistingAccount(t *testing.T) {
+	country := "GB"
+	classification := "Personal"
+	existing := &models.ResponseData{
+		ID:             AccountId,
+		OrganisationID: AccountId,
+		Attributes: &models.AccountAttributes{
+			AccountClassification: &classification,
+			Country:               &country,
+			BankID:                "400302",
+			Name:                  []string{"Samantha Holder"},
+		},
+	}
+
+	got, err := EnsureAccount(context.Background(), &existingManagementFake{existing: existing}, getEnsureRequest())
+
+	if err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+	if got.StatusCode != http.StatusOK || got.ResBody.Data != existing {
+		t.Errorf("wanted: existing account with status 200\n got: %d %v", got.StatusCode, got.ResBody.Data)
+	}
+}
+
+func TestEnsureAccount_ShouldListDifferencesWithExistingAccount(t *testing.T) {
+	country := "IE"
+	existing := &models.ResponseData{
+		ID:             AccountId,
+		OrganisationID: AccountId,
+		Attributes: &models.AccountAttributes{
+			Country: &country,
+			BankID:  "400302",
+			Name:    []string{"Sam Holder"},
+		},
+	}
+
+	_, err := EnsureAccount(context.Background(), &existingManagementFake{existing: existing}, getEnsureRequest())
+
+	if !errors.Is(err, error_handling.ErrConflict) || !errors.Is(err, error_handling.ErrAccountMismatch) {
+		t.Fatalf("wanted: %v and %v\n got: %v", error_handling.ErrConflict, error_handling.ErrAccountMismatch, err)
+	}
+
+	var mismatch *error_handling.AccountMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("wanted: *error_handling.AccountMismatchError\n got: %T", err)
+	}
+	want := []error_handling.FieldDifference{
+		{Field: "attributes.country", Requested: "GB", Existing: "IE"},
+		{Field: "attributes.name", Requested: []string{"Samantha Holder"}, Existing: []string{"Sam Holder"}},
+	}
+	if mismatch.AccountID != AccountId || !reflect.DeepEqual(mismatch.Differences, want) {
+		t.Errorf("wanted: %v\n got: %v", want, mismatch.Differences)
+	}
+}
+
+func TestEnsureAccount_ShouldReturnConflictOnAnotherAccount(t *testing.T) {
+	fake := &existingManagementFake{existing: &models.ResponseData{ID: OtherAccountId}}
+
+	_, err := EnsureAccount(context.Background(), fake, getEnsureRequest())
+
+	if !errors.Is(err, error_handling.ErrConflict) || errors.Is(err, error_handling.ErrNotFound) {
+		t.Errorf("wanted: %v\n got: %v", error_handling.ErrConflict, err)
+	}
+	if fake.fetches != 1 {
+		t.Errorf("fetches wanted: 1\n fetches got: %d", fake.fetches)
+	}
+}
+
+func TestEnsureAccount_ShouldReturnConflictWithoutAccountId(t *testing.T) {
+	fake := &existingManagementFake{existing: &models.ResponseData{}}
+	reqModel := getEnsureRequest()
+	reqModel.Data.ID = ""
+
+	_, err := EnsureAccount(context.Background(), fake, reqModel)
+
+	if !errors.Is(err, error_handling.ErrConflict) || errors.Is(err, error_handling.ErrAccountMismatch) {
+		t.Errorf("wanted: %v\n got: %v", error_handling.ErrConflict, err)
+	}
+	if fake.fetches != 0 {
+		t.Errorf("fetches wanted: 0\n fetches got: %d", fake.fetches)
+	}
+}
@@ -0,0 +1,96 @@
+package api_client
+
+import (
+	"accountapi-lib-form3/pkg/models"
+	"context"
+)
+
+// AccountIterator walks every page returned by ListAccounts, requesting the next page only when
+// the current one has been consumed. It follows the usual Next/Err pattern:
+//
+//	it := NewAccountIterator(ctx, service, &models.ListRequest{PageSize: 100})
+//	for it.Next() {
+//		account := it.Account()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type AccountIterator struct {
+	ctx        context.Context
+	management AccountManagement
+	request    models.ListRequest
+	page       []models.ResponseData
+	index      int
+	lastPage   bool
+	current    *models.ResponseData
+	err        error
+}
+
+// NewAccountIterator creates an iterator starting at reqModel.PageNumber, page size and filters
+// are kept for every page.
+func NewAccountIterator(ctx context.Context, management AccountManagement, reqModel *models.ListRequest) *AccountIterator {
+	iterator := &AccountIterator{
+		ctx:        ctx,
+		management: management,
+	}
+	if reqModel != nil {
+		iterator.request = *reqModel
+	}
+
+	return iterator
+}
+
+// Next moves the iterator to the following account, it returns false when there are no more
+// accounts or when an error happened, in that case Err returns it.
+func (i *AccountIterator) Next() bool {
+	if i.err != nil {
+		return false
+	}
+
+	for i.index >= len(i.page) {
+		if i.lastPage {
+			i.current = nil
+			return false
+		}
+
+		if !i.fetchPage() {
+			return false
+		}
+	}
+
+	i.current = &i.page[i.index]
+	i.index++
+
+	return true
+}
+
+// Account returns the account the iterator is positioned at
+func (i *AccountIterator) Account() *models.ResponseData {
+	return i.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (i *AccountIterator) Err() error {
+	return i.err
+}
+
+// fetchPage requests the current page and prepares the request of the following one. A page without
+// accounts or without a next link is considered the last one.
+func (i *AccountIterator) fetchPage() bool {
+	res, err := i.management.ListAccountsWithContext(i.ctx, &i.request)
+	if err != nil {
+		i.err = err
+		i.current = nil
+		return false
+	}
+
+	i.page = nil
+	i.index = 0
+	if res.ResBody != nil {
+		i.page = res.ResBody.Data
+	}
+
+	i.lastPage = len(i.page) == 0 || res.ResBody.Links == nil || res.ResBody.Links.Next == ""
+	i.request.PageNumber++
+
+	return true
+}
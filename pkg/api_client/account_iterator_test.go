@@ -0,0 +1,83 @@
+package api_client
+
+import (
+	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/models"
+	"context"
+	"reflect"
+	"testing"
+)
+
+// pagedManagementFake serves pages of accounts, a page has a next link unless it is the last one.
+type pagedManagementFake struct {
+	AccountManagement
+	pages     [][]string
+	failAt    int
+	requested []int
+}
+
+func (p *pagedManagementFake) ListAccountsWithContext(_ context.Context, reqModel *models.ListRequest) (*models.ListResponse, error) {
+	p.requested = append(p.requested, reqModel.PageNumber)
+	if reqModel.PageNumber == p.failAt {
+		return nil, error_handling.NewAccountError(listOperation, 500, "fake error")
+	}
+
+	out := &models.ListResponseObject{Links: &models.Link{}}
+	if reqModel.PageNumber < len(p.pages) {
+		for _, id := range p.pages[reqModel.PageNumber] {
+			out.Data = append(out.Data, models.ResponseData{ID: id})
+		}
+	}
+	if reqModel.PageNumber < len(p.pages)-1 {
+		out.Links.Next = "next"
+	}
+
+	return &models.ListResponse{ResBody: out, StatusCode: 200}, nil
+}
+
+func TestAccountIterator_ShouldWalkAllPages(t *testing.T) {
+	fake := &pagedManagementFake{
+		pages:  [][]string{{"1", "2"}, {"3"}, {"4", "5"}},
+		failAt: -1,
+	}
+
+	want := []string{"1", "2", "3", "4", "5"}
+	var got []string
+	subject := NewAccountIterator(context.Background(), fake, &models.ListRequest{PageSize: 2})
+	for subject.Next() {
+		got = append(got, subject.Account().ID)
+	}
+
+	if subject.Err() != nil {
+		t.Errorf("wanted: no error\n got: %v", subject.Err())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %v\n got: %v", want, got)
+	}
+	if !reflect.DeepEqual(fake.requested, []int{0, 1, 2}) {
+		t.Errorf("pages wanted: %v\n pages got: %v", []int{0, 1, 2}, fake.requested)
+	}
+}
+
+func TestAccountIterator_ShouldStopOnError(t *testing.T) {
+	fake := &pagedManagementFake{
+		pages:  [][]string{{"1"}, {"2"}},
+		failAt: 1,
+	}
+
+	var got []string
+	subject := NewAccountIterator(context.Background(), fake, &models.ListRequest{})
+	for subject.Next() {
+		got = append(got, subject.Account().ID)
+	}
+
+	if !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("wanted: %v\n got: %v", []string{"1"}, got)
+	}
+	if subject.Err() == nil {
+		t.Errorf("wanted: error\n got: nil")
+	}
+	if subject.Next() {
+		t.Errorf("wanted: no more accounts after an error")
+	}
+}
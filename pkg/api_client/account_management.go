@@ -2,10 +2,21 @@ package api_client
 
 import (
 	models2 "accountapi-lib-form3/pkg/models"
+	"context"
 )
 
 type AccountManagement interface {
 	CreateAccount(*models2.CreateRequest) (*models2.CreateResponse, error)
+	CreateAccountWithContext(context.Context, *models2.CreateRequest) (*models2.CreateResponse, error)
 	DeleteAccount(*models2.DeleteRequest) (*models2.DeleteResponse, error)
+	DeleteAccountWithContext(context.Context, *models2.DeleteRequest) (*models2.DeleteResponse, error)
 	FetchAccount(*models2.FetchRequest) (*models2.FetchResponse, error)
+	FetchAccountWithContext(context.Context, *models2.FetchRequest) (*models2.FetchResponse, error)
+	ListAccounts(*models2.ListRequest) (*models2.ListResponse, error)
+	ListAccountsWithContext(context.Context, *models2.ListRequest) (*models2.ListResponse, error)
+	UpdateAccount(*models2.UpdateRequest) (*models2.UpdateResponse, error)
+	UpdateAccountWithContext(context.Context, *models2.UpdateRequest) (*models2.UpdateResponse, error)
+	CreateAccounts(context.Context, []*models2.CreateRequest, BatchOptions) []CreateResult
+	FetchAccounts(context.Context, []*models2.FetchRequest, BatchOptions) []FetchResult
+	DeleteAccounts(context.Context, []*models2.DeleteRequest, BatchOptions) []DeleteResult
 }
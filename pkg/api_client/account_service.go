@@ -1,14 +1,21 @@
 package api_client
 
 import (
+	"accountapi-lib-form3/pkg/circuitbreaker"
 	"accountapi-lib-form3/pkg/configuration"
 	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/logging"
 	"accountapi-lib-form3/pkg/models"
+	"accountapi-lib-form3/pkg/tracing"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -26,6 +33,11 @@ const (
 	createOperation          = "Create"
 	deleteOperation          = "Delete"
 	fetchOperation           = "Fetch"
+	listOperation            = "List"
+	updateOperation          = "Update"
+	pageNumberParam          = "page[number]"
+	pageSizeParam            = "page[size]"
+	versionParam             = "version"
 	codeFailedMarshallingReq = 1
 	msgFailedMarshallingReq  = "failed marshalling request: "
 	codeFailedCreatingReq    = 2
@@ -38,6 +50,20 @@ const (
 	msgFailedDecodingErrRes  = "failed decoding error response: "
 	codeFailedDecodingRes    = 6
 	msgFailedDecodingRes     = "failed decoding response: "
+	codeRequestCanceled      = 7
+	msgRequestCanceled       = "request canceled: "
+	codeDeadlineExceeded     = 8
+	msgDeadlineExceeded      = "request deadline exceeded: "
+	codeMissingAccountData   = 9
+	msgMissingAccountData    = "missing account data: "
+	codeInvalidRequest       = 10
+	msgInvalidRequest        = "invalid request: "
+	codeCircuitOpen          = 11
+	msgCircuitOpen           = "circuit breaker is open: "
+	codeAccountMismatch      = 12
+	msgAccountMismatch       = "existing account differs: "
+	codeBatchAborted         = 13
+	msgBatchAborted          = "batch aborted: "
 )
 
 func NewAccountService(config *configuration.Config) AccountManagement {
@@ -46,50 +72,73 @@ func NewAccountService(config *configuration.Config) AccountManagement {
 	}
 }
 
-// CreateAccount allows to create an account by passing around some information about it
+// CreateAccount allows to create an account by passing around some information about it. Unless it is disabled
+// through the configuration, the request is validated before sending it, see models.CreateRequest Validate.
 func (a *AccountService) CreateAccount(reqModel *models.CreateRequest) (*models.CreateResponse, error) {
+	return a.CreateAccountWithContext(context.Background(), reqModel)
+}
+
+// CreateAccountWithContext works as CreateAccount, but ctx is attached to the outgoing request, so
+// cancellation and deadlines are propagated into the HTTP round trip.
+func (a *AccountService) CreateAccountWithContext(ctx context.Context, reqModel *models.CreateRequest) (_ *models.CreateResponse, err error) {
+	var accountId string
+	if reqModel.Data != nil {
+		accountId = reqModel.Data.ID
+	}
+
+	ctx, span := a.startSpan(ctx, createOperation, accountId)
+	defer func() { endSpan(span, err) }()
+
+	if (*a.config).IsRequestValidationEnabled() {
+		if err := reqModel.Validate(); err != nil {
+			return nil, newInternalError(createOperation, codeInvalidRequest, msgInvalidRequest+err.Error(), err)
+		}
+	}
 
 	inp, err := json.Marshal(reqModel)
 	if err != nil {
-		return nil, error_handling.NewAccountError(createOperation, codeFailedMarshallingReq, msgFailedMarshallingReq+err.Error())
+		return nil, newInternalError(createOperation, codeFailedMarshallingReq, msgFailedMarshallingReq+err.Error(), err)
 	}
 
 	inpReader := strings.NewReader(string(inp))
 
-	endpoint := (*a.config).GetAPIBasePath() + accountsPath
+	endpoint, err := a.accountsEndpoint(nil)
+	if err != nil {
+		return nil, newInternalError(createOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
+	}
 
-	request, err := http.NewRequest(http.MethodPost, endpoint, inpReader)
+	request, err := http.NewRequestWithContext(withLogFields(ctx, createOperation, accountId), http.MethodPost, endpoint, inpReader)
 	if err != nil {
-		return nil, error_handling.NewAccountError(createOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error())
+		return nil, newInternalError(createOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
 	}
 	request.Header.Set(dateHeader, time.Now().Format(time.RFC3339))
 	request.Header.Set(contentTypeHeader, applicationJson)
 
-	response, err := (*a.config).GetHttpClient().Do(request)
+	response, err := a.do(createOperation, request)
 	if err != nil {
-		return nil, error_handling.NewAccountError(createOperation, codeFailedInvokingBack, msgFailedInvokingBack+err.Error())
+		return nil, newInvokingBackendError(ctx, createOperation, err)
 	}
 	defer response.Body.Close()
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, error_handling.NewAccountError(createOperation, codeFailedReadingRes, msgFailedReadingRes+err.Error())
+		return nil, newReadingResponseError(ctx, createOperation, err)
 	}
 
 	if response.StatusCode != http.StatusCreated {
 		var outErr models.ResponseError
 		err = json.Unmarshal(body, &outErr)
 		if err != nil {
-			return nil, error_handling.NewAccountError(createOperation, codeFailedDecodingErrRes, msgFailedDecodingErrRes+err.Error())
+			return nil, newInternalError(createOperation, codeFailedDecodingErrRes, msgFailedDecodingErrRes+err.Error(), err)
 		}
 
-		return nil, error_handling.NewAccountError(createOperation, response.StatusCode, outErr.ErrorMessage)
+		return nil, error_handling.NewStatusError(createOperation, response.StatusCode, outErr.ErrorMessage)
 	}
 
 	var out models.ResponseObject
 	err = json.Unmarshal(body, &out)
 	if err != nil {
-		return nil, error_handling.NewAccountError(createOperation, codeFailedDecodingRes, msgFailedDecodingRes+err.Error())
+		return nil, newInternalError(createOperation, codeFailedDecodingRes, msgFailedDecodingRes+err.Error(), err)
 	}
 
 	return &models.CreateResponse{
@@ -101,38 +150,53 @@ func (a *AccountService) CreateAccount(reqModel *models.CreateRequest) (*models.
 // DeleteAccount allows to delete a particular account by using its ID and version.
 // As 404 error returns no message, it evaluates that statusCode to return an empty message to AccountError
 func (a *AccountService) DeleteAccount(reqModel *models.DeleteRequest) (*models.DeleteResponse, error) {
-	endpoint := fmt.Sprintf("%s%s/%s?version=%d", (*a.config).GetAPIBasePath(), accountsPath, reqModel.AccountId, reqModel.Version)
+	return a.DeleteAccountWithContext(context.Background(), reqModel)
+}
+
+// DeleteAccountWithContext works as DeleteAccount, but ctx is attached to the outgoing request, so
+// cancellation and deadlines are propagated into the HTTP round trip.
+func (a *AccountService) DeleteAccountWithContext(ctx context.Context, reqModel *models.DeleteRequest) (_ *models.DeleteResponse, err error) {
+	ctx, span := a.startSpan(ctx, deleteOperation, reqModel.AccountId)
+	defer func() { endSpan(span, err) }()
 
-	request, err := http.NewRequest(http.MethodDelete, endpoint, nil)
+	// the account is invalidated whatever the outcome is, a failed request may have deleted it anyway
+	defer (*a.config).GetCache().Delete(reqModel.AccountId)
+
+	endpoint, err := a.accountsEndpoint(url.Values{versionParam: {strconv.Itoa(reqModel.Version)}}, reqModel.AccountId)
+	if err != nil {
+		return nil, newInternalError(deleteOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
+	}
+
+	request, err := http.NewRequestWithContext(withLogFields(ctx, deleteOperation, reqModel.AccountId), http.MethodDelete, endpoint, nil)
 	if err != nil {
-		return nil, error_handling.NewAccountError(deleteOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error())
+		return nil, newInternalError(deleteOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
 	}
 	request.Header.Set(dateHeader, time.Now().Format(time.RFC3339))
 
-	response, err := (*a.config).GetHttpClient().Do(request)
+	response, err := a.do(deleteOperation, request)
 	if err != nil {
-		return nil, error_handling.NewAccountError(deleteOperation, codeFailedInvokingBack, msgFailedInvokingBack+err.Error())
+		return nil, newInvokingBackendError(ctx, deleteOperation, err)
 	}
 	defer response.Body.Close()
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, error_handling.NewAccountError(deleteOperation, codeFailedReadingRes, msgFailedReadingRes+err.Error())
+		return nil, newReadingResponseError(ctx, deleteOperation, err)
 	}
 
 	if response.StatusCode != http.StatusNoContent {
 
 		if response.StatusCode == http.StatusNotFound {
-			return nil, error_handling.NewAccountError(deleteOperation, response.StatusCode, "")
+			return nil, error_handling.NewStatusError(deleteOperation, response.StatusCode, "")
 		}
 
 		var outErr models.ResponseError
 		err = json.Unmarshal(body, &outErr)
 		if err != nil {
-			return nil, error_handling.NewAccountError(deleteOperation, codeFailedDecodingErrRes, msgFailedDecodingErrRes+err.Error())
+			return nil, newInternalError(deleteOperation, codeFailedDecodingErrRes, msgFailedDecodingErrRes+err.Error(), err)
 		}
 
-		return nil, error_handling.NewAccountError(deleteOperation, response.StatusCode, outErr.ErrorMessage)
+		return nil, error_handling.NewStatusError(deleteOperation, response.StatusCode, outErr.ErrorMessage)
 	}
 
 	return &models.DeleteResponse{
@@ -142,44 +206,426 @@ func (a *AccountService) DeleteAccount(reqModel *models.DeleteRequest) (*models.
 
 // FetchAccount allows to get a particular account by searching for its ID
 func (a *AccountService) FetchAccount(reqModel *models.FetchRequest) (*models.FetchResponse, error) {
-	endpoint := fmt.Sprintf("%s%s/%s", (*a.config).GetAPIBasePath(), accountsPath, reqModel.AccountId)
+	return a.FetchAccountWithContext(context.Background(), reqModel)
+}
+
+// FetchAccountWithContext works as FetchAccount, but ctx is attached to the outgoing request, so
+// cancellation and deadlines are propagated into the HTTP round trip. When a cache is configured, a cached
+// account is returned without invoking the account API.
+func (a *AccountService) FetchAccountWithContext(ctx context.Context, reqModel *models.FetchRequest) (_ *models.FetchResponse, err error) {
+	ctx, span := a.startSpan(ctx, fetchOperation, reqModel.AccountId)
+	defer func() { endSpan(span, err) }()
+
+	cache := (*a.config).GetCache()
+	if cached, ok := cache.Get(reqModel.AccountId); ok {
+		var out models.ResponseObject
+		if json.Unmarshal(cached, &out) == nil {
+			return &models.FetchResponse{
+				ResBody:    &out,
+				StatusCode: http.StatusOK,
+			}, nil
+		}
+		cache.Delete(reqModel.AccountId)
+	}
+	// a deletion or an update sent while the account is being fetched makes the answer stale
+	generation := cache.Generation(reqModel.AccountId)
+
+	endpoint, err := a.accountsEndpoint(nil, reqModel.AccountId)
+	if err != nil {
+		return nil, newInternalError(fetchOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
+	}
 
-	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	request, err := http.NewRequestWithContext(withLogFields(ctx, fetchOperation, reqModel.AccountId), http.MethodGet, endpoint, nil)
 	if err != nil {
-		return nil, error_handling.NewAccountError(fetchOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error())
+		return nil, newInternalError(fetchOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
 	}
 	request.Header.Set(dateHeader, time.Now().Format(time.RFC3339))
 	request.Header.Set(acceptHeader, jsonAPIMediaType)
 
-	response, err := (*a.config).GetHttpClient().Do(request)
+	response, err := a.do(fetchOperation, request)
 	if err != nil {
-		return nil, error_handling.NewAccountError(fetchOperation, codeFailedInvokingBack, msgFailedInvokingBack+err.Error())
+		return nil, newInvokingBackendError(ctx, fetchOperation, err)
 	}
 	defer response.Body.Close()
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, error_handling.NewAccountError(fetchOperation, codeFailedReadingRes, msgFailedReadingRes+err.Error())
+		return nil, newReadingResponseError(ctx, fetchOperation, err)
 	}
 
 	if response.StatusCode != http.StatusOK {
 		var outErr models.ResponseError
 		err = json.Unmarshal(body, &outErr)
 		if err != nil {
-			return nil, error_handling.NewAccountError(fetchOperation, codeFailedDecodingErrRes, msgFailedDecodingErrRes+err.Error())
+			return nil, newInternalError(fetchOperation, codeFailedDecodingErrRes, msgFailedDecodingErrRes+err.Error(), err)
 		}
 
-		return nil, error_handling.NewAccountError(fetchOperation, response.StatusCode, outErr.ErrorMessage)
+		return nil, error_handling.NewStatusError(fetchOperation, response.StatusCode, outErr.ErrorMessage)
 	}
 
 	var out models.ResponseObject
 	err = json.Unmarshal(body, &out)
 	if err != nil {
-		return nil, error_handling.NewAccountError(createOperation, codeFailedDecodingRes, msgFailedDecodingRes+err.Error())
+		return nil, newInternalError(fetchOperation, codeFailedDecodingRes, msgFailedDecodingRes+err.Error(), err)
 	}
+	cache.SetIfGeneration(reqModel.AccountId, body, generation)
 
 	return &models.FetchResponse{
 		ResBody:    &out,
 		StatusCode: response.StatusCode,
 	}, nil
 }
+
+// startSpan starts the span of an operation, accountId is omitted when it is empty.
+func (a *AccountService) startSpan(ctx context.Context, operation string, accountId string) (context.Context, tracing.Span) {
+	ctx, span := (*a.config).GetTracer().Start(ctx, "accountapi."+operation)
+
+	span.SetAttribute(tracing.AttributeOperation, operation)
+	if accountId != "" {
+		span.SetAttribute(tracing.AttributeAccountID, accountId)
+	}
+
+	return ctx, span
+}
+
+// endSpan records err, if any, and ends span.
+func endSpan(span tracing.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+// do sends request through the configured http.Client once the circuit breaker and the request limiter allow
+// it, recording the metrics of operation and the status code in the span of the request context. The limiter is
+// released when the response body is closed.
+func (a *AccountService) do(operation string, request *http.Request) (*http.Response, error) {
+	recorder := (*a.config).GetMetricsRecorder()
+	span := tracing.SpanFromContext(request.Context())
+	span.SetAttribute(tracing.AttributeMethod, request.Method)
+
+	breakerDone, err := (*a.config).GetCircuitBreaker().Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	queuedSince := time.Now()
+	release, err := (*a.config).GetRequestLimiter().Acquire(request.Context())
+	recorder.RequestQueued(operation, time.Since(queuedSince))
+	if err != nil {
+		breakerDone(circuitbreaker.Ignore)
+		return nil, err
+	}
+
+	recorder.RequestStarted(operation)
+	start := time.Now()
+
+	response, err := (*a.config).GetHttpClient().Do(request)
+
+	statusCode := 0
+	if err == nil {
+		statusCode = response.StatusCode
+		span.SetAttribute(tracing.AttributeStatusCode, statusCode)
+		response.Body = &releasingBody{ReadCloser: response.Body, release: release}
+	} else {
+		release()
+	}
+	recorder.RequestFinished(operation, statusCode, time.Since(start))
+	breakerDone(breakerResult(request.Context(), statusCode, err))
+
+	return response, err
+}
+
+// breakerResult tells the circuit breaker whether the account API failed, requests canceled through their
+// context say nothing about it.
+func breakerResult(ctx context.Context, statusCode int, err error) circuitbreaker.Result {
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return circuitbreaker.Ignore
+	case err != nil || statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError:
+		return circuitbreaker.Failure
+	default:
+		return circuitbreaker.Success
+	}
+}
+
+// releasingBody releases the request limiter once the response body is closed, so a concurrency slot is held
+// until the response has been read.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (r *releasingBody) Close() error {
+	r.once.Do(r.release)
+	return r.ReadCloser.Close()
+}
+
+// internalErrorKinds classifies internal codes, so they can be checked with errors.Is
+var internalErrorKinds = map[int]error_handling.Kind{
+	codeFailedMarshallingReq: error_handling.KindEncode,
+	codeFailedCreatingReq:    error_handling.KindRequest,
+	codeFailedInvokingBack:   error_handling.KindTransport,
+	codeFailedReadingRes:     error_handling.KindTransport,
+	codeFailedDecodingErrRes: error_handling.KindDecode,
+	codeFailedDecodingRes:    error_handling.KindDecode,
+	codeRequestCanceled:      error_handling.KindCanceled,
+	codeDeadlineExceeded:     error_handling.KindDeadlineExceeded,
+	codeMissingAccountData:   error_handling.KindValidation,
+	codeInvalidRequest:       error_handling.KindValidation,
+	codeCircuitOpen:          error_handling.KindCircuitOpen,
+	codeAccountMismatch:      error_handling.KindConflict,
+	codeBatchAborted:         error_handling.KindCanceled,
+}
+
+func newInternalError(operation string, code int, message string, cause error) error {
+	return error_handling.NewInternalError(operation, internalErrorKinds[code], code, message, cause)
+}
+
+// newInvokingBackendError builds the error returned when http.Client.Do fails. Cancellation and deadlines
+// coming from ctx, as well as requests rejected by the circuit breaker, are reported with their own codes,
+// so they can be told apart from a backend failure.
+func newInvokingBackendError(ctx context.Context, operation string, err error) error {
+	if errors.Is(err, circuitbreaker.ErrOpen) {
+		return newInternalError(operation, codeCircuitOpen, msgCircuitOpen+"request was not sent", err)
+	}
+	if ctxErr := newContextError(ctx, operation, err); ctxErr != nil {
+		return ctxErr
+	}
+
+	return newInternalError(operation, codeFailedInvokingBack, msgFailedInvokingBack+err.Error(), err)
+}
+
+// newReadingResponseError builds the error returned when the response body cannot be read, cancellation and
+// deadlines coming from ctx are reported with their own codes, as in newInvokingBackendError.
+func newReadingResponseError(ctx context.Context, operation string, err error) error {
+	if ctxErr := newContextError(ctx, operation, err); ctxErr != nil {
+		return ctxErr
+	}
+
+	return newInternalError(operation, codeFailedReadingRes, msgFailedReadingRes+err.Error(), err)
+}
+
+// newContextError returns the error of a request canceled or timed out through ctx, or nil when err has
+// nothing to do with ctx.
+func newContextError(ctx context.Context, operation string, err error) error {
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		return newInternalError(operation, codeRequestCanceled, msgRequestCanceled+err.Error(), err)
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return newInternalError(operation, codeDeadlineExceeded, msgDeadlineExceeded+err.Error(), err)
+	default:
+		return nil
+	}
+}
+
+// withLogFields adds the operation and, when it is known, the account ID to ctx, so they are logged
+// along with the request.
+func withLogFields(ctx context.Context, operation string, accountId string) context.Context {
+	if accountId == "" {
+		return logging.WithFields(ctx, logging.F(logging.FieldOperation, operation))
+	}
+
+	return logging.WithFields(ctx, logging.F(logging.FieldOperation, operation), logging.F(logging.FieldAccountID, accountId))
+}
+
+// ListAccounts allows to get a page of accounts, page and filters are taken from reqModel
+func (a *AccountService) ListAccounts(reqModel *models.ListRequest) (*models.ListResponse, error) {
+	return a.ListAccountsWithContext(context.Background(), reqModel)
+}
+
+// ListAccountsWithContext works as ListAccounts, but ctx is attached to the outgoing request, so
+// cancellation and deadlines are propagated into the HTTP round trip.
+func (a *AccountService) ListAccountsWithContext(ctx context.Context, reqModel *models.ListRequest) (_ *models.ListResponse, err error) {
+	ctx, span := a.startSpan(ctx, listOperation, "")
+	defer func() { endSpan(span, err) }()
+
+	endpoint, err := a.accountsEndpoint(listQuery(reqModel))
+	if err != nil {
+		return nil, newInternalError(listOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
+	}
+
+	request, err := http.NewRequestWithContext(withLogFields(ctx, listOperation, ""), http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, newInternalError(listOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
+	}
+	request.Header.Set(dateHeader, time.Now().Format(time.RFC3339))
+	request.Header.Set(acceptHeader, jsonAPIMediaType)
+
+	response, err := a.do(listOperation, request)
+	if err != nil {
+		return nil, newInvokingBackendError(ctx, listOperation, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, newReadingResponseError(ctx, listOperation, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		var outErr models.ResponseError
+		err = json.Unmarshal(body, &outErr)
+		if err != nil {
+			return nil, newInternalError(listOperation, codeFailedDecodingErrRes, msgFailedDecodingErrRes+err.Error(), err)
+		}
+
+		return nil, error_handling.NewStatusError(listOperation, response.StatusCode, outErr.ErrorMessage)
+	}
+
+	var out models.ListResponseObject
+	err = json.Unmarshal(body, &out)
+	if err != nil {
+		return nil, newInternalError(listOperation, codeFailedDecodingRes, msgFailedDecodingRes+err.Error(), err)
+	}
+
+	return &models.ListResponse{
+		ResBody:    &out,
+		StatusCode: response.StatusCode,
+	}, nil
+}
+
+// accountsEndpoint appends accountsPath and, when it is given, the escaped account ID to the base path of the
+// account API. query is merged into the query of the base path, if any.
+func (a *AccountService) accountsEndpoint(query url.Values, accountId ...string) (string, error) {
+	endpoint, err := url.Parse((*a.config).GetAPIBasePath())
+	if err != nil {
+		return "", err
+	}
+
+	rawPath := endpoint.EscapedPath() + accountsPath
+	endpoint.Path += accountsPath
+	for _, id := range accountId {
+		rawPath += "/" + url.PathEscape(id)
+		endpoint.Path += "/" + id
+	}
+	endpoint.RawPath = rawPath
+
+	if len(query) > 0 {
+		merged := endpoint.Query()
+		for name, values := range query {
+			merged[name] = append(merged[name], values...)
+		}
+		endpoint.RawQuery = merged.Encode()
+	}
+
+	return endpoint.String(), nil
+}
+
+// listQuery translates a ListRequest into page[...] and filter[...] query parameters. Zero values are
+// omitted, so the account API applies its own defaults.
+func listQuery(reqModel *models.ListRequest) url.Values {
+	query := url.Values{}
+	if reqModel == nil {
+		return query
+	}
+
+	if reqModel.PageNumber > 0 {
+		query.Set(pageNumberParam, strconv.Itoa(reqModel.PageNumber))
+	}
+	if reqModel.PageSize > 0 {
+		query.Set(pageSizeParam, strconv.Itoa(reqModel.PageSize))
+	}
+
+	if f := reqModel.Filter; f != nil {
+		filters := map[string]string{
+			"bank_id":        f.BankID,
+			"bank_id_code":   f.BankIDCode,
+			"account_number": f.AccountNumber,
+			"iban":           f.Iban,
+			"country":        f.Country,
+			"customer_id":    f.CustomerID,
+		}
+		for name, value := range filters {
+			if value != "" {
+				query.Set("filter["+name+"]", value)
+			}
+		}
+	}
+
+	return query
+}
+
+// UpdateAccount allows to modify attributes of an existing account by sending a PATCH request. reqModel.Data.Version
+// has to be the current version of the account, when it is not, the returned AccountError wraps
+// error_handling.ErrVersionConflict.
+func (a *AccountService) UpdateAccount(reqModel *models.UpdateRequest) (*models.UpdateResponse, error) {
+	return a.UpdateAccountWithContext(context.Background(), reqModel)
+}
+
+// UpdateAccountWithContext works as UpdateAccount, but ctx is attached to the outgoing request, so
+// cancellation and deadlines are propagated into the HTTP round trip.
+func (a *AccountService) UpdateAccountWithContext(ctx context.Context, reqModel *models.UpdateRequest) (_ *models.UpdateResponse, err error) {
+	var accountId string
+	if reqModel.Data != nil {
+		accountId = reqModel.Data.ID
+	}
+
+	ctx, span := a.startSpan(ctx, updateOperation, accountId)
+	defer func() { endSpan(span, err) }()
+
+	if reqModel.Data == nil || reqModel.Data.ID == "" || reqModel.Data.Version == nil {
+		return nil, newInternalError(updateOperation, codeMissingAccountData, msgMissingAccountData+"id and version are required", nil)
+	}
+
+	// the account is invalidated whatever the outcome is, a failed request may have updated it anyway
+	defer (*a.config).GetCache().Delete(accountId)
+
+	inp, err := json.Marshal(reqModel)
+	if err != nil {
+		return nil, newInternalError(updateOperation, codeFailedMarshallingReq, msgFailedMarshallingReq+err.Error(), err)
+	}
+
+	inpReader := strings.NewReader(string(inp))
+
+	endpoint, err := a.accountsEndpoint(nil, reqModel.Data.ID)
+	if err != nil {
+		return nil, newInternalError(updateOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
+	}
+
+	request, err := http.NewRequestWithContext(withLogFields(ctx, updateOperation, reqModel.Data.ID), http.MethodPatch, endpoint, inpReader)
+	if err != nil {
+		return nil, newInternalError(updateOperation, codeFailedCreatingReq, msgFailedCreatingReq+err.Error(), err)
+	}
+	request.Header.Set(dateHeader, time.Now().Format(time.RFC3339))
+	request.Header.Set(contentTypeHeader, jsonAPIMediaType)
+	request.Header.Set(acceptHeader, jsonAPIMediaType)
+
+	response, err := a.do(updateOperation, request)
+	if err != nil {
+		return nil, newInvokingBackendError(ctx, updateOperation, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, newReadingResponseError(ctx, updateOperation, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		var outErr models.ResponseError
+		err = json.Unmarshal(body, &outErr)
+		if err != nil {
+			return nil, newInternalError(updateOperation, codeFailedDecodingErrRes, msgFailedDecodingErrRes+err.Error(), err)
+		}
+
+		if response.StatusCode == http.StatusConflict {
+			return nil, error_handling.NewAccountErrorWithCause(updateOperation, response.StatusCode, outErr.ErrorMessage, error_handling.ErrVersionConflict)
+		}
+
+		return nil, error_handling.NewStatusError(updateOperation, response.StatusCode, outErr.ErrorMessage)
+	}
+
+	var out models.ResponseObject
+	err = json.Unmarshal(body, &out)
+	if err != nil {
+		return nil, newInternalError(updateOperation, codeFailedDecodingRes, msgFailedDecodingRes+err.Error(), err)
+	}
+
+	return &models.UpdateResponse{
+		ResBody:    &out,
+		StatusCode: response.StatusCode,
+	}, nil
+}
@@ -0,0 +1,288 @@
+package api_client
+
+import (
+	"accountapi-lib-form3/pkg/accountapitest"
+	"accountapi-lib-form3/pkg/cache"
+	"accountapi-lib-form3/pkg/configuration"
+	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/logging"
+	"accountapi-lib-form3/pkg/metrics"
+	"accountapi-lib-form3/pkg/models"
+	"accountapi-lib-form3/pkg/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const OtherAccountId = "3b9ef55d-fe5e-434b-9f60-d5a0f9758887"
+
+func getServerSubject(t *testing.T) (*accountapitest.Server, AccountManagement) {
+	server := accountapitest.NewServer()
+	t.Cleanup(server.Close)
+
+	config := server.ConfigBuilder().Build()
+	return server, NewAccountService(&config)
+}
+
+func getCreateRequest(id string) *models.CreateRequest {
+	var input models.CreateRequest
+	_ = json.Unmarshal([]byte(CreationRequest), &input)
+	input.Data.ID = id
+
+	return &input
+}
+
+func TestAccountServiceWithServer_ShouldCreateFetchAndDelete(t *testing.T) {
+	_, subject := getServerSubject(t)
+
+	created, err := subject.CreateAccount(getCreateRequest(AccountId))
+	if err != nil || created.StatusCode != 201 {
+		t.Fatalf("create wanted: 201\n got: %v %v", created, err)
+	}
+
+	fetched, err := subject.FetchAccount(&models.FetchRequest{AccountId: AccountId})
+	if err != nil || fetched.ResBody.Data.ID != AccountId {
+		t.Fatalf("fetch wanted: %s\n got: %v %v", AccountId, fetched, err)
+	}
+
+	deleted, err := subject.DeleteAccount(&models.DeleteRequest{AccountId: AccountId, Version: 0})
+	if err != nil || deleted.StatusCode != 204 {
+		t.Fatalf("delete wanted: 204\n got: %v %v", deleted, err)
+	}
+
+	_, err = subject.FetchAccount(&models.FetchRequest{AccountId: AccountId})
+	if !errors.Is(err, error_handling.ErrNotFound) {
+		t.Errorf("fetch after delete wanted: %v\n got: %v", error_handling.ErrNotFound, err)
+	}
+}
+
+func TestAccountServiceWithServer_ShouldReturnRealisticErrors(t *testing.T) {
+	server, subject := getServerSubject(t)
+	_, _ = subject.CreateAccount(getCreateRequest(AccountId))
+
+	_, err := subject.CreateAccount(getCreateRequest(AccountId))
+	if !errors.Is(err, error_handling.ErrConflict) {
+		t.Errorf("duplicate wanted: %v\n got: %v", error_handling.ErrConflict, err)
+	}
+
+	_, err = subject.CreateAccount(getCreateRequest("123"))
+	if !errors.Is(err, error_handling.ErrValidation) {
+		t.Errorf("invalid id wanted: %v\n got: %v", error_handling.ErrValidation, err)
+	}
+
+	_, err = subject.DeleteAccount(&models.DeleteRequest{AccountId: AccountId, Version: 3})
+	if !errors.Is(err, error_handling.ErrConflict) {
+		t.Errorf("wrong version wanted: %v\n got: %v", error_handling.ErrConflict, err)
+	}
+
+	_, err = subject.DeleteAccount(&models.DeleteRequest{AccountId: OtherAccountId, Version: 0})
+	if !errors.Is(err, error_handling.ErrNotFound) {
+		t.Errorf("unknown id wanted: %v\n got: %v", error_handling.ErrNotFound, err)
+	}
+
+	if got := len(server.Accounts()); got != 1 {
+		t.Errorf("accounts wanted: 1\n accounts got: %d", got)
+	}
+}
+
+func TestAccountServiceWithServer_ShouldListEveryPage(t *testing.T) {
+	server, subject := getServerSubject(t)
+	for _, id := range []string{AccountId, OtherAccountId, "e6f3eed0-3f37-416a-9cfc-187c2caadb69"} {
+		server.Seed(models.ResponseData{ID: id, Attributes: &models.AccountAttributes{BankID: "400302"}})
+	}
+
+	var got []string
+	it := NewAccountIterator(context.Background(), subject, &models.ListRequest{
+		PageSize: 2,
+		Filter:   &models.ListFilter{BankID: "400302"},
+	})
+	for it.Next() {
+		got = append(got, it.Account().ID)
+	}
+
+	if it.Err() != nil || len(got) != 3 {
+		t.Errorf("wanted: 3 accounts\n got: %v %v", got, it.Err())
+	}
+}
+
+func TestAccountServiceWithServer_ShouldUpdateWithVersion(t *testing.T) {
+	_, subject := getServerSubject(t)
+	_, _ = subject.CreateAccount(getCreateRequest(AccountId))
+
+	res, err := UpdateAccountWithRetry(context.Background(), subject, AccountId, func(attributes *models.AccountAttributes) error {
+		attributes.AlternativeNames = []string{"Sam H."}
+		return nil
+	}, 1)
+	if err != nil || *res.ResBody.Data.Version != 1 || res.ResBody.Data.Attributes.AlternativeNames[0] != "Sam H." {
+		t.Fatalf("wanted: version 1 with new alternative name\n got: %v %v", res, err)
+	}
+
+	version := int64(0)
+	_, err = subject.UpdateAccount(&models.UpdateRequest{Data: &models.AccountData{ID: AccountId, Version: &version}})
+	if !errors.Is(err, error_handling.ErrVersionConflict) {
+		t.Errorf("stale version wanted: %v\n got: %v", error_handling.ErrVersionConflict, err)
+	}
+}
+
+func TestAccountServiceWithServer_ShouldLogOperationAndAccountId(t *testing.T) {
+	server := accountapitest.NewServer()
+	t.Cleanup(server.Close)
+
+	var out strings.Builder
+	config := server.ConfigBuilder().WithLogger(logging.NewJSONLogger(&out, logging.LevelInfo)).Build()
+	subject := NewAccountService(&config)
+
+	_, _ = subject.FetchAccount(&models.FetchRequest{AccountId: AccountId})
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("wanted: JSON line\n got: %q", out.String())
+	}
+	if got["operation"] != fetchOperation || got["account_id"] != AccountId || got["status"] != float64(404) {
+		t.Errorf("wanted: Fetch of %s with status 404\n got: %v", AccountId, got)
+	}
+}
+
+func TestAccountServiceWithServer_ShouldRecordMetricsByOperation(t *testing.T) {
+	server := accountapitest.NewServer()
+	t.Cleanup(server.Close)
+
+	recorder := metrics.NewPrometheusRecorder()
+	config := server.ConfigBuilder().WithMetricsRecorder(recorder).Build()
+	subject := NewAccountService(&config)
+
+	_, _ = subject.CreateAccount(getCreateRequest(AccountId))
+	_, _ = subject.FetchAccount(&models.FetchRequest{AccountId: AccountId})
+	_, _ = subject.FetchAccount(&models.FetchRequest{AccountId: OtherAccountId})
+
+	var out strings.Builder
+	_ = recorder.Write(&out)
+
+	for _, want := range []string{
+		`accountapi_requests_total{operation="Create",status_class="2xx"} 1`,
+		`accountapi_requests_total{operation="Fetch",status_class="2xx"} 1`,
+		`accountapi_requests_total{operation="Fetch",status_class="4xx"} 1`,
+		`accountapi_request_duration_seconds_count{operation="Fetch"} 2`,
+		`accountapi_requests_in_flight{operation="Fetch"} 0`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("wanted: %s\n got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestAccountServiceWithServer_ShouldTraceEveryOperation(t *testing.T) {
+	server := accountapitest.NewServer()
+	t.Cleanup(server.Close)
+
+	recorder := tracing.NewRecorder()
+	config := server.ConfigBuilder().WithTracer(recorder).Build()
+	subject := NewAccountService(&config)
+
+	ctx, parent := recorder.Start(context.Background(), "gateway")
+	_, _ = subject.CreateAccountWithContext(ctx, getCreateRequest(AccountId))
+	_, _ = subject.FetchAccountWithContext(ctx, &models.FetchRequest{AccountId: OtherAccountId})
+	parent.End()
+
+	spans := recorder.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("spans wanted: 3\n spans got: %d", len(spans))
+	}
+
+	create, fetch := spans[0], spans[1]
+	if create.Name != "accountapi.Create" || create.Attributes[tracing.AttributeStatusCode] != 201 || len(create.Errors) != 0 {
+		t.Errorf("wanted: successful create span\n got: %v", create)
+	}
+	if fetch.Name != "accountapi.Fetch" || fetch.Attributes[tracing.AttributeAccountID] != OtherAccountId || !errors.Is(fetch.Errors[0], error_handling.ErrNotFound) {
+		t.Errorf("wanted: fetch span with not found error\n got: %v", fetch)
+	}
+	if create.ParentSpanID != parent.SpanContext().SpanID || create.SpanContext.TraceID != parent.SpanContext().TraceID {
+		t.Errorf("wanted: create span child of gateway span\n got: %v", create)
+	}
+}
+
+func TestAccountServiceWithServer_ShouldAnswerFetchFromCacheUntilInvalidated(t *testing.T) {
+	server := accountapitest.NewServer()
+	t.Cleanup(server.Close)
+	config := server.ConfigBuilder().
+		WithCache(cache.NewLRU(10, time.Minute)).
+		Build()
+	subject := NewAccountService(&config)
+
+	if _, err := subject.CreateAccount(getCreateRequest(AccountId)); err != nil {
+		t.Fatalf("create wanted: nil\n got: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := subject.FetchAccount(&models.FetchRequest{AccountId: AccountId}); err != nil {
+			t.Fatalf("fetch %d wanted: nil\n got: %v", i, err)
+		}
+	}
+	if got := config.GetCacheStats(); got != (configuration.CacheStats{Hits: 1, Misses: 1}) {
+		t.Errorf("wanted: 1 hit and 1 miss\n got: %+v", got)
+	}
+
+	version := int64(0)
+	_, err := subject.UpdateAccount(&models.UpdateRequest{Data: &models.AccountData{
+		ID:         AccountId,
+		Version:    &version,
+		Attributes: &models.AccountAttributes{AlternativeNames: []string{"Sam"}},
+	}})
+	if err != nil {
+		t.Fatalf("update wanted: nil\n got: %v", err)
+	}
+	fetched, err := subject.FetchAccount(&models.FetchRequest{AccountId: AccountId})
+	if err != nil || !reflect.DeepEqual(fetched.ResBody.Data.Attributes.AlternativeNames, []string{"Sam"}) {
+		t.Fatalf("fetch after update wanted: [Sam]\n got: %v %v", fetched, err)
+	}
+
+	if _, err := subject.DeleteAccount(&models.DeleteRequest{AccountId: AccountId, Version: 1}); err != nil {
+		t.Fatalf("delete wanted: nil\n got: %v", err)
+	}
+	if _, err := subject.FetchAccount(&models.FetchRequest{AccountId: AccountId}); !errors.Is(err, error_handling.ErrNotFound) {
+		t.Errorf("fetch after delete wanted: %v\n got: %v", error_handling.ErrNotFound, err)
+	}
+	if got := config.GetCacheStats(); got != (configuration.CacheStats{Hits: 1, Misses: 3}) {
+		t.Errorf("wanted: 1 hit and 3 misses\n got: %+v", got)
+	}
+}
+
+// TestAccountServiceWithServer_ShouldShareConfigAcrossGoroutines is meant to be run with -race.
+func TestAccountServiceWithServer_ShouldShareConfigAcrossGoroutines(t *testing.T) {
+	server := accountapitest.NewServer()
+	t.Cleanup(server.Close)
+	builder := server.ConfigBuilder().WithCache(cache.NewLRU(10, time.Minute)).WithMaxConcurrentRequests(4)
+	base := builder.Build()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			config := base
+			if i%2 == 0 {
+				config = configuration.NewDefaultConfigBuilder().From(base).WithoutRequestValidation().Build()
+			}
+			subject := NewAccountService(&config)
+
+			id := fmt.Sprintf("%08d-5cb7-49b5-b61c-ea0f7036e4b6", i)
+			if _, err := subject.CreateAccount(getCreateRequest(id)); err != nil {
+				t.Errorf("wanted: nil\n got: %v", err)
+			}
+			if _, err := subject.FetchAccount(&models.FetchRequest{AccountId: id}); err != nil {
+				t.Errorf("wanted: nil\n got: %v", err)
+			}
+		}(i)
+	}
+	builder.WithHost("unreachable").WithPort("1")
+	wg.Wait()
+
+	if got := len(server.Accounts()); got != 8 {
+		t.Errorf("wanted: 8 accounts\n got: %d", got)
+	}
+}
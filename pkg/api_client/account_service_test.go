@@ -1,22 +1,30 @@
 package api_client
 
 import (
+	"accountapi-lib-form3/pkg/cache"
+	"accountapi-lib-form3/pkg/circuitbreaker"
 	"accountapi-lib-form3/pkg/configuration"
+	"accountapi-lib-form3/pkg/error_handling"
 	"accountapi-lib-form3/pkg/models"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 const (
 	RightJsonResponse = `{"data":{"attributes":{"account_classification":"Personal","account_matching_opt_out":false,"alternative_names":["Sam Holder"],"bank_id":"400302","bank_id_code":"GBDSC","base_currency":"GBP","bic":"NWBKGB42","country":"GB","joint_account":false,"name":["Samantha Holder"],"secondary_identification":"A1B2C3D4"},"created_on":"2021-10-15T03:19:57.796Z","id":"ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6","modified_on":"2021-10-15T03:19:57.796Z","organisation_id":"ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6","type":"accounts","version":0},"links":{"self":"/v1/organisation/accounts/ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6"}}`
 	WrongJsonResponse = `{"error_message":"id is not a valid uuid"}`
 	CreationRequest   = `{"data":{"id":"ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6","organisation_id":"ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6","type":"accounts","attributes":{"country":"GB","base_currency":"GBP","bank_id":"400302","bank_id_code":"GBDSC","customer_id":"234","bic":"NWBKGB42","name":["Samantha Holder"],"alternative_names":["Sam Holder"],"account_classification":"Personal","joint_account":false,"account_matching_opt_out":false,"secondary_identification":"A1B2C3D4"}}}`
+	RightListResponse = `{"data":[{"attributes":{"bank_id":"400302","bank_id_code":"GBDSC","country":"GB","name":["Samantha Holder"]},"id":"ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6","organisation_id":"ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6","type":"accounts","version":0}],"links":{"first":"/v1/organisation/accounts?page%5Bnumber%5D=first","last":"/v1/organisation/accounts?page%5Bnumber%5D=last","self":"/v1/organisation/accounts"}}`
 	AccountId         = "ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6"
 	RightPort         = "80"
 )
@@ -40,6 +48,46 @@ func (t *transportFake) RoundTrip(req *http.Request) (resp *http.Response, err e
 	}, nil
 }
 
+// blockingTransportFake waits until the request context is done, emulating a backend that never answers.
+type blockingTransportFake struct{}
+
+func (t *blockingTransportFake) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+// blockingBodyTransportFake answers at once, but its response body blocks until the request context is done,
+// emulating a backend that stops sending the body.
+type blockingBodyTransportFake struct{}
+
+func (t *blockingBodyTransportFake) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: &blockingBody{ctx: req.Context()}}, nil
+}
+
+type blockingBody struct {
+	ctx context.Context
+}
+
+func (b *blockingBody) Read([]byte) (int, error) {
+	<-b.ctx.Done()
+	return 0, b.ctx.Err()
+}
+
+func (b *blockingBody) Close() error {
+	return nil
+}
+
+func getBlockingBuilder() configuration.Config {
+	client := &http.Client{
+		Transport: &blockingTransportFake{},
+	}
+
+	return configuration.NewDefaultConfigBuilder().
+		WithHost("fake").
+		WithHttpClient(client).
+		Build()
+}
+
 func getBuilder(resp string, statusCode int, isError bool, port string) configuration.Config {
 	client := &http.Client{
 		Transport: &transportFake{
@@ -196,7 +244,7 @@ func TestAccountService_ShouldReturnFailureWhenCreatingRequest(t *testing.T) {
 	subject := NewAccountService(&builder)
 	var got error
 
-	_, got = subject.CreateAccount(&models.CreateRequest{})
+	_, got = subject.CreateAccount(getCreateRequest(AccountId))
 
 	if !strings.Contains(got.Error(), want) {
 		t.Errorf("wanted: %s\n got: %s", want, got)
@@ -220,7 +268,7 @@ func TestAccountService_ShouldReturnFailureWhenInvokingBackend(t *testing.T) {
 	subject := NewAccountService(&builder)
 	var got error
 
-	_, got = subject.CreateAccount(&models.CreateRequest{})
+	_, got = subject.CreateAccount(getCreateRequest(AccountId))
 	if !strings.Contains(got.Error(), want) {
 		t.Errorf("wanted: %s\n got: %s", want, got)
 	}
@@ -258,7 +306,7 @@ func TestAccountService_ShouldReturnFailureWhenDecodingResponse(t *testing.T) {
 
 			switch {
 			case strings.Contains(v.testName, "Creation"):
-				_, got = subject.CreateAccount(&models.CreateRequest{})
+				_, got = subject.CreateAccount(getCreateRequest(AccountId))
 				if !strings.Contains(got.Error(), v.want) {
 					t.Errorf("wanted string: %s\n message got: %s", v.want, got)
 				}
@@ -276,3 +324,435 @@ func TestAccountService_ShouldReturnFailureWhenDecodingResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountService_ShouldReturnCanceledWhenContextIsCanceled(t *testing.T) {
+
+	want := "7 - request canceled"
+	builder := getBlockingBuilder()
+	subject := NewAccountService(&builder)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var got error
+
+	_, got = subject.CreateAccountWithContext(ctx, getCreateRequest(AccountId))
+	if !strings.Contains(got.Error(), want) {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+
+	_, got = subject.DeleteAccountWithContext(ctx, &models.DeleteRequest{})
+	if !strings.Contains(got.Error(), want) {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+
+	_, got = subject.FetchAccountWithContext(ctx, &models.FetchRequest{})
+	if !strings.Contains(got.Error(), want) {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+}
+
+func TestAccountService_ShouldReturnDeadlineExceededWhenContextExpires(t *testing.T) {
+
+	want := "8 - request deadline exceeded"
+	builder := getBlockingBuilder()
+	subject := NewAccountService(&builder)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var got error
+
+	_, got = subject.CreateAccountWithContext(ctx, getCreateRequest(AccountId))
+	if !strings.Contains(got.Error(), want) {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+
+	_, got = subject.DeleteAccountWithContext(ctx, &models.DeleteRequest{})
+	if !strings.Contains(got.Error(), want) {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+
+	_, got = subject.FetchAccountWithContext(ctx, &models.FetchRequest{})
+	if !strings.Contains(got.Error(), want) {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+}
+
+func TestAccountService_ShouldReturnDeadlineExceededWhileReadingBody(t *testing.T) {
+	config := configuration.NewDefaultConfigBuilder().
+		WithHost("fake").
+		WithHttpClient(&http.Client{Transport: &blockingBodyTransportFake{}}).
+		Build()
+	subject := NewAccountService(&config)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, got := subject.FetchAccountWithContext(ctx, &models.FetchRequest{AccountId: AccountId})
+
+	if !errors.Is(got, error_handling.ErrDeadlineExceeded) || !strings.Contains(got.Error(), "8 - request deadline exceeded") {
+		t.Errorf("wanted: %v with code 8\n got: %v", error_handling.ErrDeadlineExceeded, got)
+	}
+}
+
+func TestAccountService_ShouldReturnSuccessfulList(t *testing.T) {
+	var responseObject models.ListResponseObject
+	_ = json.Unmarshal([]byte(RightListResponse), &responseObject)
+	want := &models.ListResponse{
+		ResBody:    &responseObject,
+		StatusCode: 200,
+	}
+
+	builder := getBuilder(RightListResponse, 200, false, RightPort)
+	subject := NewAccountService(&builder)
+	got, _ := subject.ListAccounts(&models.ListRequest{})
+
+	if !reflect.DeepEqual(*got, *want) {
+		t.Errorf("wanted: %s\n got: %s", getStringStruct(want), getStringStruct(got))
+	}
+}
+
+func TestAccountService_ShouldReturnFailedList(t *testing.T) {
+
+	want := "List: 400 - id is not a valid uuid"
+
+	builder := getBuilder(WrongJsonResponse, 400, false, RightPort)
+	subject := NewAccountService(&builder)
+	_, got := subject.ListAccounts(&models.ListRequest{})
+
+	if got.Error() != want {
+		t.Errorf("wanted: %s\n got: %s", want, got.Error())
+	}
+}
+
+func TestAccountService_ShouldBuildListQuery(t *testing.T) {
+
+	want := "filter%5Bbank_id%5D=400302&filter%5Bcountry%5D=GB&page%5Bnumber%5D=2&page%5Bsize%5D=50"
+	got := listQuery(&models.ListRequest{
+		PageNumber: 2,
+		PageSize:   50,
+		Filter: &models.ListFilter{
+			BankID:  "400302",
+			Country: "GB",
+		},
+	}).Encode()
+
+	if got != want {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+}
+
+func getUpdateRequest() models.UpdateRequest {
+	var input models.CreateRequest
+	_ = json.Unmarshal([]byte(CreationRequest), &input)
+	version := int64(0)
+	input.Data.Version = &version
+
+	return models.UpdateRequest{Data: input.Data}
+}
+
+func TestAccountService_ShouldReturnSuccessfulUpdate(t *testing.T) {
+	input := getUpdateRequest()
+
+	var responseObject models.ResponseObject
+	_ = json.Unmarshal([]byte(RightJsonResponse), &responseObject)
+	want := &models.UpdateResponse{
+		ResBody:    &responseObject,
+		StatusCode: 200,
+	}
+
+	builder := getBuilder(RightJsonResponse, 200, false, RightPort)
+	subject := NewAccountService(&builder)
+	got, _ := subject.UpdateAccount(&input)
+
+	if !reflect.DeepEqual(*got, *want) {
+		t.Errorf("wanted: %s\n got: %s", getStringStruct(want), getStringStruct(got))
+	}
+}
+
+func TestAccountService_ShouldReturnVersionConflictOnUpdate(t *testing.T) {
+	input := getUpdateRequest()
+
+	want := "Update: 409 - invalid version"
+
+	builder := getBuilder(`{"error_message":"invalid version"}`, 409, false, RightPort)
+	subject := NewAccountService(&builder)
+	_, got := subject.UpdateAccount(&input)
+
+	if got.Error() != want {
+		t.Errorf("wanted: %s\n got: %s", want, got.Error())
+	}
+	if !errors.Is(got, error_handling.ErrVersionConflict) {
+		t.Errorf("wanted: %v\n got: %v", error_handling.ErrVersionConflict, got)
+	}
+}
+
+func TestAccountService_ShouldRequireVersionOnUpdate(t *testing.T) {
+
+	want := "9 - missing account data"
+
+	builder := getBuilder(RightJsonResponse, 200, false, RightPort)
+	subject := NewAccountService(&builder)
+	_, got := subject.UpdateAccount(&models.UpdateRequest{Data: &models.AccountData{ID: AccountId}})
+
+	if !strings.Contains(got.Error(), want) {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+}
+
+func TestAccountService_ShouldRequireIdOnUpdate(t *testing.T) {
+	fake := &urlTransportFake{}
+	config := configuration.NewDefaultConfigBuilder().
+		WithHost("fake").
+		WithHttpClient(&http.Client{Transport: fake}).
+		Build()
+	subject := NewAccountService(&config)
+
+	input := getUpdateRequest()
+	input.Data.ID = ""
+	_, got := subject.UpdateAccount(&input)
+
+	var acctErr *error_handling.AccountError
+	if !errors.As(got, &acctErr) || acctErr.GetCode() != codeMissingAccountData {
+		t.Errorf("wanted: code %d\n got: %v", codeMissingAccountData, got)
+	}
+	if len(fake.urls) != 0 {
+		t.Errorf("wanted: no request sent\n got: %v", fake.urls)
+	}
+}
+
+func TestAccountService_ShouldMatchSentinelErrors(t *testing.T) {
+
+	dataTable := []struct {
+		testName   string
+		resp       string
+		statusCode int
+		isError    bool
+		port       string
+		want       error
+	}{
+		{"notFound", WrongJsonResponse, 404, false, RightPort, error_handling.ErrNotFound},
+		{"conflict", WrongJsonResponse, 409, false, RightPort, error_handling.ErrConflict},
+		{"validation", WrongJsonResponse, 400, false, RightPort, error_handling.ErrValidation},
+		{"server", WrongJsonResponse, 500, false, RightPort, error_handling.ErrServer},
+		{"transport", "", 200, true, RightPort, error_handling.ErrTransport},
+		{"request", "", 200, false, "80 ", error_handling.ErrRequest},
+		{"decode", "EOF", 400, false, RightPort, error_handling.ErrDecode},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			builder := getBuilder(v.resp, v.statusCode, v.isError, v.port)
+			subject := NewAccountService(&builder)
+
+			_, got := subject.CreateAccount(getCreateRequest(AccountId))
+			if !errors.Is(got, v.want) {
+				t.Errorf("create wanted: %v\n got: %v", v.want, got)
+			}
+
+			_, got = subject.DeleteAccount(&models.DeleteRequest{})
+			if !errors.Is(got, v.want) {
+				t.Errorf("delete wanted: %v\n got: %v", v.want, got)
+			}
+
+			_, got = subject.FetchAccount(&models.FetchRequest{})
+			if !errors.Is(got, v.want) {
+				t.Errorf("fetch wanted: %v\n got: %v", v.want, got)
+			}
+		})
+	}
+}
+
+func TestAccountService_ShouldPreserveContextError(t *testing.T) {
+	builder := getBlockingBuilder()
+	subject := NewAccountService(&builder)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, got := subject.FetchAccountWithContext(ctx, &models.FetchRequest{})
+
+	if !errors.Is(got, error_handling.ErrCanceled) || !errors.Is(got, context.Canceled) {
+		t.Errorf("wanted: %v and %v\n got: %v", error_handling.ErrCanceled, context.Canceled, got)
+	}
+}
+
+func TestAccountService_ShouldValidateCreationRequest(t *testing.T) {
+	input := getCreateRequest("123")
+	input.Data.Attributes.BaseCurrency = "GBX"
+
+	client := &http.Client{
+		Transport: &transportFake{respJson: RightJsonResponse, statusCode: 201},
+	}
+	builder := configuration.NewDefaultConfigBuilder().
+		WithHost("fake").
+		WithHttpClient(client).
+		Build()
+	subject := NewAccountService(&builder)
+
+	_, got := subject.CreateAccount(input)
+
+	var validationErrs models.ValidationErrors
+	if !errors.As(got, &validationErrs) || len(validationErrs) != 2 {
+		t.Fatalf("wanted: 2 field errors\n got: %v", got)
+	}
+	if !errors.Is(got, error_handling.ErrValidation) || !strings.Contains(got.Error(), "10 - invalid request") {
+		t.Errorf("wanted: %v with code 10\n got: %v", error_handling.ErrValidation, got)
+	}
+}
+
+// invalidatingTransportFake invalidates the requested account in cache while the request is in flight, as a
+// deletion sent at the same time would do, and answers with RightJsonResponse.
+type invalidatingTransportFake struct {
+	cache configuration.Cache
+}
+
+func (i *invalidatingTransportFake) RoundTrip(req *http.Request) (*http.Response, error) {
+	i.cache.Delete(AccountId)
+
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(RightJsonResponse))}, nil
+}
+
+func TestAccountService_ShouldNotCacheAccountInvalidatedWhileFetching(t *testing.T) {
+	fake := &invalidatingTransportFake{}
+	config := configuration.NewDefaultConfigBuilder().
+		WithHost("fake").
+		WithHttpClient(&http.Client{Transport: fake}).
+		WithCache(cache.NewLRU(10, 0)).
+		Build()
+	fake.cache = config.GetCache()
+	subject := NewAccountService(&config)
+
+	if _, err := subject.FetchAccount(&models.FetchRequest{AccountId: AccountId}); err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+
+	if _, ok := config.GetCache().Get(AccountId); ok {
+		t.Errorf("wanted: stale account not cached\n got: cached")
+	}
+}
+
+// urlTransportFake records the URL of every request and answers them with 404.
+type urlTransportFake struct {
+	urls []string
+}
+
+func (u *urlTransportFake) RoundTrip(req *http.Request) (*http.Response, error) {
+	u.urls = append(u.urls, req.URL.String())
+
+	return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func TestAccountService_ShouldBuildEndpointsFromBaseURL(t *testing.T) {
+	fake := &urlTransportFake{}
+	config := configuration.NewDefaultConfigBuilder().
+		WithBaseURL("https://gateway/payments-gw/accountapi/v1?tenant=a%26b").
+		WithHttpClient(&http.Client{Transport: fake}).
+		Build()
+	subject := NewAccountService(&config)
+
+	_, _ = subject.CreateAccount(getCreateRequest(AccountId))
+	_, _ = subject.FetchAccount(&models.FetchRequest{AccountId: "a/b?c"})
+	_, _ = subject.DeleteAccount(&models.DeleteRequest{AccountId: AccountId, Version: 2})
+	_, _ = subject.ListAccounts(&models.ListRequest{PageNumber: 1})
+
+	want := []string{
+		"https://gateway/payments-gw/accountapi/v1/organisation/accounts?tenant=a%26b",
+		"https://gateway/payments-gw/accountapi/v1/organisation/accounts/a%2Fb%3Fc?tenant=a%26b",
+		"https://gateway/payments-gw/accountapi/v1/organisation/accounts/" + AccountId + "?tenant=a%26b&version=2",
+		"https://gateway/payments-gw/accountapi/v1/organisation/accounts?page%5Bnumber%5D=1&tenant=a%26b",
+	}
+	if !reflect.DeepEqual(fake.urls, want) {
+		t.Errorf("wanted: %v\n got: %v", want, fake.urls)
+	}
+}
+
+// gateTransportFake counts requests and answers them once gate is closed.
+type gateTransportFake struct {
+	calls int32
+	gate  chan struct{}
+}
+
+func (g *gateTransportFake) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&g.calls, 1)
+	<-g.gate
+
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func TestAccountService_ShouldWaitForConcurrencySlot(t *testing.T) {
+	fake := &gateTransportFake{gate: make(chan struct{})}
+	config := configuration.NewDefaultConfigBuilder().
+		WithHost("fake").
+		WithHttpClient(&http.Client{Transport: fake}).
+		WithMaxConcurrentRequests(1).
+		Build()
+	subject := NewAccountService(&config)
+
+	done := make(chan error)
+	go func() {
+		_, err := subject.FetchAccount(&models.FetchRequest{AccountId: AccountId})
+		done <- err
+	}()
+	for atomic.LoadInt32(&fake.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := subject.FetchAccountWithContext(ctx, &models.FetchRequest{AccountId: AccountId})
+
+	if !errors.Is(err, error_handling.ErrDeadlineExceeded) {
+		t.Errorf("wanted: %v\n got: %v", error_handling.ErrDeadlineExceeded, err)
+	}
+	if got := atomic.LoadInt32(&fake.calls); got != 1 {
+		t.Errorf("calls wanted: 1, the second request must not be sent\n calls got: %d", got)
+	}
+
+	close(fake.gate)
+	if err := <-done; err != nil {
+		t.Fatalf("first fetch wanted: nil\n got: %v", err)
+	}
+	if _, err := subject.FetchAccount(&models.FetchRequest{AccountId: AccountId}); err != nil {
+		t.Errorf("wanted: slot released after the first fetch\n got: %v", err)
+	}
+}
+
+func TestAccountService_ShouldFailFastWhenCircuitIsOpen(t *testing.T) {
+	config := configuration.NewDefaultConfigBuilder().
+		WithHost("fake").
+		WithHttpClient(&http.Client{Transport: &transportFake{respJson: "{}", statusCode: http.StatusServiceUnavailable}}).
+		WithCircuitBreaker(circuitbreaker.Settings{ConsecutiveFailures: 2, CoolDown: time.Minute}).
+		Build()
+	subject := NewAccountService(&config)
+
+	for i := 0; i < 2; i++ {
+		if _, err := subject.FetchAccount(&models.FetchRequest{AccountId: AccountId}); !errors.Is(err, error_handling.ErrServer) {
+			t.Fatalf("fetch %d wanted: %v\n got: %v", i, error_handling.ErrServer, err)
+		}
+	}
+
+	_, err := subject.FetchAccount(&models.FetchRequest{AccountId: AccountId})
+
+	var accountErr *error_handling.AccountError
+	if !errors.As(err, &accountErr) || !errors.Is(err, error_handling.ErrCircuitOpen) {
+		t.Fatalf("wanted: %v\n got: %v", error_handling.ErrCircuitOpen, err)
+	}
+	if accountErr.GetCode() != codeCircuitOpen || !errors.Is(err, circuitbreaker.ErrOpen) {
+		t.Errorf("wanted: code %d caused by %v\n got: %d %v", codeCircuitOpen, circuitbreaker.ErrOpen, accountErr.GetCode(), accountErr.Unwrap())
+	}
+}
+
+func TestAccountService_ShouldNotCountCanceledRequestsAsFailures(t *testing.T) {
+	config := configuration.NewDefaultConfigBuilder().
+		WithHost("fake").
+		WithHttpClient(&http.Client{Transport: &blockingTransportFake{}}).
+		WithCircuitBreaker(circuitbreaker.Settings{ConsecutiveFailures: 1}).
+		Build()
+	subject := NewAccountService(&config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := subject.FetchAccountWithContext(ctx, &models.FetchRequest{AccountId: AccountId}); !errors.Is(err, error_handling.ErrDeadlineExceeded) {
+		t.Fatalf("wanted: %v\n got: %v", error_handling.ErrDeadlineExceeded, err)
+	}
+
+	if got := config.GetCircuitBreaker().State(); got != circuitbreaker.StateClosed {
+		t.Errorf("wanted: %v\n got: %v", circuitbreaker.StateClosed, got)
+	}
+}
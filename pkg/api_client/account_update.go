@@ -0,0 +1,65 @@
+package api_client
+
+import (
+	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/models"
+	"context"
+	"errors"
+)
+
+const (
+	accountType        = "accounts"
+	defaultMaxAttempts = 3
+)
+
+// AccountMutation modifies attributes of a fetched account, returning an error aborts the update.
+type AccountMutation func(attributes *models.AccountAttributes) error
+
+// UpdateAccountWithRetry fetches the account identified by accountId, applies mutate to its attributes and
+// sends the update with the fetched version. When another client modified the account in the meantime,
+// the update fails with a version conflict, so the whole cycle is repeated up to maxAttempts times.
+// A maxAttempts lower than 1 falls back to 3 attempts.
+func UpdateAccountWithRetry(ctx context.Context, management AccountManagement, accountId string, mutate AccountMutation, maxAttempts int) (*models.UpdateResponse, error) {
+	if maxAttempts < 1 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		var fetched *models.FetchResponse
+		fetched, err = management.FetchAccountWithContext(ctx, &models.FetchRequest{AccountId: accountId})
+		if err != nil {
+			return nil, err
+		}
+
+		if fetched.ResBody == nil || fetched.ResBody.Data == nil {
+			return nil, newInternalError(updateOperation, codeMissingAccountData, msgMissingAccountData+"fetched account is empty", nil)
+		}
+
+		data := fetched.ResBody.Data
+		attributes := models.AccountAttributes{}
+		if data.Attributes != nil {
+			attributes = *data.Attributes
+		}
+
+		if err = mutate(&attributes); err != nil {
+			return nil, err
+		}
+
+		var updated *models.UpdateResponse
+		updated, err = management.UpdateAccountWithContext(ctx, &models.UpdateRequest{
+			Data: &models.AccountData{
+				Attributes:     &attributes,
+				ID:             data.ID,
+				OrganisationID: data.OrganisationID,
+				Type:           accountType,
+				Version:        data.Version,
+			},
+		})
+		if !errors.Is(err, error_handling.ErrVersionConflict) {
+			return updated, err
+		}
+	}
+
+	return nil, err
+}
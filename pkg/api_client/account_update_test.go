@@ -0,0 +1,97 @@
+package api_client
+
+import (
+	"accountapi-lib-form3/pkg/error_handling"
+	"accountapi-lib-form3/pkg/models"
+	"context"
+	"errors"
+	"testing"
+)
+
+// versionedManagementFake keeps one account and rejects updates whose version is not the current one.
+// Every fetch is followed by a concurrent modification while concurrentUpdates is greater than 0.
+type versionedManagementFake struct {
+	AccountManagement
+	version           int64
+	name              string
+	concurrentUpdates int
+	updates           int
+}
+
+func (v *versionedManagementFake) FetchAccountWithContext(_ context.Context, reqModel *models.FetchRequest) (*models.FetchResponse, error) {
+	version := v.version
+	data := &models.ResponseData{
+		ID:         reqModel.AccountId,
+		Version:    &version,
+		Attributes: &models.AccountAttributes{Name: []string{v.name}},
+	}
+	if v.concurrentUpdates > 0 {
+		v.concurrentUpdates--
+		v.version++
+	}
+
+	return &models.FetchResponse{ResBody: &models.ResponseObject{Data: data}, StatusCode: 200}, nil
+}
+
+func (v *versionedManagementFake) UpdateAccountWithContext(_ context.Context, reqModel *models.UpdateRequest) (*models.UpdateResponse, error) {
+	v.updates++
+	if *reqModel.Data.Version != v.version {
+		return nil, error_handling.NewAccountErrorWithCause(updateOperation, 409, "invalid version", error_handling.ErrVersionConflict)
+	}
+	v.version++
+	v.name = reqModel.Data.Attributes.Name[0]
+
+	return &models.UpdateResponse{StatusCode: 200}, nil
+}
+
+func renameTo(name string) AccountMutation {
+	return func(attributes *models.AccountAttributes) error {
+		attributes.Name = []string{name}
+		return nil
+	}
+}
+
+func TestUpdateAccountWithRetry_ShouldRetryOnVersionConflict(t *testing.T) {
+	fake := &versionedManagementFake{name: "old", concurrentUpdates: 2}
+
+	_, err := UpdateAccountWithRetry(context.Background(), fake, AccountId, renameTo("new"), 3)
+
+	if err != nil {
+		t.Errorf("wanted: no error\n got: %v", err)
+	}
+	if fake.name != "new" {
+		t.Errorf("wanted: new\n got: %s", fake.name)
+	}
+	if fake.updates != 3 {
+		t.Errorf("updates wanted: 3\n updates got: %d", fake.updates)
+	}
+}
+
+func TestUpdateAccountWithRetry_ShouldGiveUpAfterMaxAttempts(t *testing.T) {
+	fake := &versionedManagementFake{name: "old", concurrentUpdates: 5}
+
+	_, err := UpdateAccountWithRetry(context.Background(), fake, AccountId, renameTo("new"), 2)
+
+	if !errors.Is(err, error_handling.ErrVersionConflict) {
+		t.Errorf("wanted: %v\n got: %v", error_handling.ErrVersionConflict, err)
+	}
+	if fake.updates != 2 {
+		t.Errorf("updates wanted: 2\n updates got: %d", fake.updates)
+	}
+}
+
+func TestUpdateAccountWithRetry_ShouldAbortWhenMutationFails(t *testing.T) {
+	fake := &versionedManagementFake{name: "old"}
+	want := errors.New("mutation error")
+
+	_, err := UpdateAccountWithRetry(context.Background(), fake, AccountId, func(*models.AccountAttributes) error {
+		return want
+	}, 3)
+
+	if err != want {
+		t.Errorf("wanted: %v\n got: %v", want, err)
+	}
+	if fake.updates != 0 {
+		t.Errorf("updates wanted: 0\n updates got: %d", fake.updates)
+	}
+}
@@ -0,0 +1,101 @@
+// Package cache provides an in-memory configuration.Cache that keeps the most recently used accounts for a
+// limited time.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU keeps up to maxEntries fetched accounts, evicting the least recently used one when it is full. Entries
+// expire once ttl has elapsed since they were set. It is safe for concurrent use.
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+type entry struct {
+	accountId string
+	body      []byte
+	expiresAt time.Time
+}
+
+// NewLRU creates a cache of maxEntries accounts, a maxEntries lower than 1 means no limit and a ttl lower than 1
+// means that entries never expire.
+func NewLRU(maxEntries int, ttl time.Duration) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+// Get returns the body cached for accountId, unless it is missing or expired.
+func (l *LRU) Get(accountId string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[accountId]
+	if !ok {
+		return nil, false
+	}
+
+	cached := element.Value.(*entry)
+	if l.ttl > 0 && !l.now().Before(cached.expiresAt) {
+		l.remove(element)
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+	return cached.body, true
+}
+
+// Set caches body for accountId, evicting the least recently used account when the cache is full.
+func (l *LRU) Set(accountId string, body []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt := l.now().Add(l.ttl)
+	if element, ok := l.entries[accountId]; ok {
+		cached := element.Value.(*entry)
+		cached.body, cached.expiresAt = body, expiresAt
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.entries[accountId] = l.order.PushFront(&entry{accountId: accountId, body: body, expiresAt: expiresAt})
+	if l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		l.remove(l.order.Back())
+	}
+}
+
+// Delete removes accountId from the cache, if it is cached.
+func (l *LRU) Delete(accountId string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[accountId]; ok {
+		l.remove(element)
+	}
+}
+
+// Len returns the number of cached accounts, including expired ones that were not requested since they expired.
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+// remove deletes element, it must be invoked holding the lock.
+func (l *LRU) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.entries, element.Value.(*entry).accountId)
+}
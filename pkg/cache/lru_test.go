@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// getLRUStub returns a cache whose clock only moves when the returned function is invoked.
+func getLRUStub(maxEntries int, ttl time.Duration) (*LRU, func(time.Duration)) {
+	now := time.Date(2021, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	lru := NewLRU(maxEntries, ttl)
+	lru.now = func() time.Time { return now }
+
+	return lru, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestLRU_ShouldEvictLeastRecentlyUsed(t *testing.T) {
+	subject, _ := getLRUStub(2, 0)
+
+	subject.Set("a", []byte("1"))
+	subject.Set("b", []byte("2"))
+	_, _ = subject.Get("a")
+	subject.Set("c", []byte("3"))
+
+	if _, ok := subject.Get("b"); ok {
+		t.Errorf("wanted: b evicted\n got: cached")
+	}
+	for _, id := range []string{"a", "c"} {
+		if _, ok := subject.Get(id); !ok {
+			t.Errorf("wanted: %s cached\n got: missing", id)
+		}
+	}
+	if got := subject.Len(); got != 2 {
+		t.Errorf("len wanted: 2\n len got: %d", got)
+	}
+}
+
+func TestLRU_ShouldExpireEntries(t *testing.T) {
+	subject, advance := getLRUStub(0, time.Minute)
+	subject.Set("a", []byte("1"))
+
+	advance(59 * time.Second)
+	if got, ok := subject.Get("a"); !ok || string(got) != "1" {
+		t.Fatalf("wanted: 1\n got: %s %v", got, ok)
+	}
+
+	advance(time.Second)
+	if _, ok := subject.Get("a"); ok {
+		t.Errorf("wanted: expired\n got: cached")
+	}
+	if got := subject.Len(); got != 0 {
+		t.Errorf("len wanted: 0\n len got: %d", got)
+	}
+}
+
+func TestLRU_ShouldReplaceAndDeleteEntries(t *testing.T) {
+	subject, _ := getLRUStub(1, 0)
+
+	subject.Set("a", []byte("1"))
+	subject.Set("a", []byte("2"))
+	if got, _ := subject.Get("a"); string(got) != "2" {
+		t.Fatalf("wanted: 2\n got: %s", got)
+	}
+
+	subject.Delete("a")
+	if _, ok := subject.Get("a"); ok {
+		t.Errorf("wanted: deleted\n got: cached")
+	}
+}
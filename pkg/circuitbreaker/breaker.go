@@ -0,0 +1,268 @@
+// Package circuitbreaker stops sending requests to a backend that keeps failing, so callers fail fast instead
+// of waiting for a timeout. After a cool-down period, a few probes are allowed to check whether it recovered.
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while the circuit is open, or half-open with every probe in flight.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State of a Breaker.
+type State int
+
+const (
+	// StateClosed lets every request through while failures are counted.
+	StateClosed State = iota
+	// StateOpen rejects every request until the cool-down period has elapsed.
+	StateOpen
+	// StateHalfOpen lets a limited number of probes through, they close the circuit when all of them succeed.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Result is the outcome of a request allowed by a Breaker.
+type Result int
+
+const (
+	// Success resets the consecutive failures and, in half-open state, counts as a successful probe.
+	Success Result = iota
+	// Failure counts towards opening the circuit and, in half-open state, opens it again.
+	Failure
+	// Ignore neither counts as success nor failure, e.g. when the caller canceled the request.
+	Ignore
+)
+
+const (
+	defaultConsecutiveFailures = 5
+	defaultCoolDown            = 5 * time.Second
+	defaultProbes              = 1
+)
+
+// Settings defines when a Breaker opens and how it recovers. At least one threshold has to be set, otherwise
+// the circuit opens after 5 consecutive failures.
+type Settings struct {
+	// ConsecutiveFailures opens the circuit after that many failures in a row, 0 disables it.
+	ConsecutiveFailures int
+	// FailureRatio opens the circuit when the ratio of failures in the current window reaches it, 0 disables it.
+	FailureRatio float64
+	// MinRequests is the number of requests in the current window needed before evaluating FailureRatio.
+	MinRequests int
+	// Window is how often counts of the closed state are reset, 0 means they are only reset when the circuit closes.
+	Window time.Duration
+	// CoolDown is how long the circuit stays open before letting probes through, 5 seconds by default.
+	CoolDown time.Duration
+	// Probes is the number of requests let through in half-open state, all of them have to succeed to close
+	// the circuit. 1 by default.
+	Probes int
+	// OnStateChange is invoked on every state change, outside the lock of the Breaker.
+	OnStateChange func(from State, to State)
+}
+
+// Breaker implements a circuit breaker, it is safe for concurrent use. A nil Breaker allows every request.
+type Breaker struct {
+	mu       sync.Mutex
+	settings Settings
+	now      func() time.Time
+
+	state       State
+	generation  uint64
+	windowStart time.Time
+	openedAt    time.Time
+
+	requests    int
+	failures    int
+	consecutive int
+
+	probesInFlight int
+	probeSuccesses int
+}
+
+// New creates a closed Breaker, zero settings take their default value.
+func New(settings Settings) *Breaker {
+	if settings.ConsecutiveFailures <= 0 && settings.FailureRatio <= 0 {
+		settings.ConsecutiveFailures = defaultConsecutiveFailures
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = defaultCoolDown
+	}
+	if settings.Probes < 1 {
+		settings.Probes = defaultProbes
+	}
+
+	b := &Breaker{settings: settings, now: time.Now}
+	b.windowStart = b.now()
+
+	return b
+}
+
+// State returns the current state, an open circuit whose cool-down has elapsed is reported as half-open.
+func (b *Breaker) State() State {
+	if b == nil {
+		return StateClosed
+	}
+
+	b.mu.Lock()
+	transition := b.checkCoolDown()
+	state := b.state
+	b.mu.Unlock()
+
+	b.notify(transition)
+	return state
+}
+
+// Allow returns ErrOpen when the request must not be sent, otherwise it returns a function that must be
+// invoked with the result of the request.
+func (b *Breaker) Allow() (func(Result), error) {
+	if b == nil {
+		return func(Result) {}, nil
+	}
+
+	b.mu.Lock()
+	transition := b.checkCoolDown()
+
+	switch b.state {
+	case StateOpen:
+		b.mu.Unlock()
+		b.notify(transition)
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.probesInFlight >= b.settings.Probes-b.probeSuccesses {
+			b.mu.Unlock()
+			b.notify(transition)
+			return nil, ErrOpen
+		}
+		b.probesInFlight++
+	}
+
+	generation := b.generation
+	b.mu.Unlock()
+	b.notify(transition)
+
+	var once sync.Once
+	return func(result Result) {
+		once.Do(func() { b.done(generation, result) })
+	}, nil
+}
+
+type transition struct {
+	from State
+	to   State
+}
+
+// done records the result of a request, results of requests allowed before the last state change are discarded.
+func (b *Breaker) done(generation uint64, result Result) {
+	b.mu.Lock()
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	var changed *transition
+	switch b.state {
+	case StateClosed:
+		changed = b.recordClosed(result)
+	case StateHalfOpen:
+		changed = b.recordHalfOpen(result)
+	}
+	b.mu.Unlock()
+
+	b.notify(changed)
+}
+
+func (b *Breaker) recordClosed(result Result) *transition {
+	if b.settings.Window > 0 && b.now().Sub(b.windowStart) >= b.settings.Window {
+		b.requests, b.failures = 0, 0
+		b.windowStart = b.now()
+	}
+
+	switch result {
+	case Success:
+		b.requests++
+		b.consecutive = 0
+	case Failure:
+		b.requests++
+		b.failures++
+		b.consecutive++
+	default:
+		return nil
+	}
+
+	if b.shouldOpen() {
+		return b.setState(StateOpen)
+	}
+
+	return nil
+}
+
+func (b *Breaker) shouldOpen() bool {
+	if b.settings.ConsecutiveFailures > 0 && b.consecutive >= b.settings.ConsecutiveFailures {
+		return true
+	}
+
+	return b.settings.FailureRatio > 0 && b.requests >= b.settings.MinRequests &&
+		float64(b.failures)/float64(b.requests) >= b.settings.FailureRatio
+}
+
+func (b *Breaker) recordHalfOpen(result Result) *transition {
+	b.probesInFlight--
+
+	switch result {
+	case Success:
+		b.probeSuccesses++
+		if b.probeSuccesses >= b.settings.Probes {
+			return b.setState(StateClosed)
+		}
+	case Failure:
+		return b.setState(StateOpen)
+	}
+
+	return nil
+}
+
+// checkCoolDown moves an open circuit to half-open once the cool-down period has elapsed, it must be invoked
+// holding the lock.
+func (b *Breaker) checkCoolDown() *transition {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.settings.CoolDown {
+		return b.setState(StateHalfOpen)
+	}
+
+	return nil
+}
+
+// setState resets the counts of the new state, it must be invoked holding the lock.
+func (b *Breaker) setState(state State) *transition {
+	changed := &transition{from: b.state, to: state}
+
+	b.state = state
+	b.generation++
+	b.requests, b.failures, b.consecutive = 0, 0, 0
+	b.probesInFlight, b.probeSuccesses = 0, 0
+	b.windowStart = b.now()
+	if state == StateOpen {
+		b.openedAt = b.now()
+	}
+
+	return changed
+}
+
+func (b *Breaker) notify(changed *transition) {
+	if changed != nil && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(changed.from, changed.to)
+	}
+}
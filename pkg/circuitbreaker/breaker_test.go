@@ -0,0 +1,170 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// getBreakerStub returns a breaker whose clock only moves when the returned function is invoked.
+func getBreakerStub(settings Settings) (*Breaker, func(time.Duration)) {
+	now := time.Date(2021, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	breaker := New(settings)
+	breaker.now = func() time.Time { return now }
+	breaker.windowStart = now
+
+	return breaker, func(d time.Duration) { now = now.Add(d) }
+}
+
+func record(t *testing.T, breaker *Breaker, results ...Result) {
+	t.Helper()
+
+	for _, result := range results {
+		done, err := breaker.Allow()
+		if err != nil {
+			t.Fatalf("wanted: request allowed\n got: %v", err)
+		}
+		done(result)
+	}
+}
+
+func TestBreaker_ShouldOpenAfterConsecutiveFailures(t *testing.T) {
+	subject, _ := getBreakerStub(Settings{ConsecutiveFailures: 2})
+
+	record(t, subject, Failure, Success, Failure)
+	if got := subject.State(); got != StateClosed {
+		t.Fatalf("wanted: %v\n got: %v", StateClosed, got)
+	}
+
+	record(t, subject, Failure)
+	if got := subject.State(); got != StateOpen {
+		t.Fatalf("wanted: %v\n got: %v", StateOpen, got)
+	}
+	if _, err := subject.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("wanted: %v\n got: %v", ErrOpen, err)
+	}
+}
+
+func TestBreaker_ShouldOpenWhenFailureRatioIsReached(t *testing.T) {
+	subject, advance := getBreakerStub(Settings{FailureRatio: 0.5, MinRequests: 4, Window: time.Minute})
+
+	record(t, subject, Failure, Success, Failure)
+	advance(time.Minute)
+	record(t, subject, Success, Failure, Success)
+	if got := subject.State(); got != StateClosed {
+		t.Fatalf("wanted: %v after window reset\n got: %v", StateClosed, got)
+	}
+
+	record(t, subject, Failure)
+	if got := subject.State(); got != StateOpen {
+		t.Errorf("wanted: %v\n got: %v", StateOpen, got)
+	}
+}
+
+func TestBreaker_ShouldCloseWhenEveryProbeSucceeds(t *testing.T) {
+	subject, advance := getBreakerStub(Settings{ConsecutiveFailures: 1, CoolDown: time.Second, Probes: 2})
+	record(t, subject, Failure)
+
+	advance(time.Second)
+	first, err := subject.Allow()
+	if err != nil {
+		t.Fatalf("wanted: first probe allowed\n got: %v", err)
+	}
+	second, err := subject.Allow()
+	if err != nil {
+		t.Fatalf("wanted: second probe allowed\n got: %v", err)
+	}
+	if _, err := subject.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("wanted: %v while probes are in flight\n got: %v", ErrOpen, err)
+	}
+
+	first(Success)
+	if got := subject.State(); got != StateHalfOpen {
+		t.Fatalf("wanted: %v\n got: %v", StateHalfOpen, got)
+	}
+	second(Success)
+	if got := subject.State(); got != StateClosed {
+		t.Errorf("wanted: %v\n got: %v", StateClosed, got)
+	}
+}
+
+func TestBreaker_ShouldReopenWhenProbeFails(t *testing.T) {
+	subject, advance := getBreakerStub(Settings{ConsecutiveFailures: 1, CoolDown: time.Second})
+	record(t, subject, Failure)
+
+	advance(500 * time.Millisecond)
+	if _, err := subject.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("wanted: %v during cool-down\n got: %v", ErrOpen, err)
+	}
+
+	advance(500 * time.Millisecond)
+	record(t, subject, Failure)
+	if got := subject.State(); got != StateOpen {
+		t.Errorf("wanted: %v\n got: %v", StateOpen, got)
+	}
+}
+
+func TestBreaker_ShouldFreeProbeWhenResultIsIgnored(t *testing.T) {
+	subject, advance := getBreakerStub(Settings{ConsecutiveFailures: 1, CoolDown: time.Second})
+	record(t, subject, Failure)
+	advance(time.Second)
+
+	record(t, subject, Ignore, Success)
+	if got := subject.State(); got != StateClosed {
+		t.Errorf("wanted: %v\n got: %v", StateClosed, got)
+	}
+}
+
+func TestBreaker_ShouldDiscardResultsOfPreviousState(t *testing.T) {
+	subject, advance := getBreakerStub(Settings{ConsecutiveFailures: 1, CoolDown: time.Second})
+
+	late, _ := subject.Allow()
+	record(t, subject, Failure)
+	advance(time.Second)
+
+	late(Failure)
+	if got := subject.State(); got != StateHalfOpen {
+		t.Errorf("wanted: %v\n got: %v", StateHalfOpen, got)
+	}
+}
+
+func TestBreaker_ShouldNotifyStateChanges(t *testing.T) {
+	var got []State
+	subject, advance := getBreakerStub(Settings{
+		ConsecutiveFailures: 1,
+		CoolDown:            time.Second,
+		OnStateChange: func(from State, to State) {
+			got = append(got, from, to)
+		},
+	})
+
+	record(t, subject, Failure)
+	advance(time.Second)
+	record(t, subject, Success)
+
+	want := []State{StateClosed, StateOpen, StateOpen, StateHalfOpen, StateHalfOpen, StateClosed}
+	if len(got) != len(want) {
+		t.Fatalf("wanted: %v\n got: %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("wanted: %v\n got: %v", want, got)
+			break
+		}
+	}
+}
+
+func TestBreaker_ShouldAllowEveryRequestWhenNil(t *testing.T) {
+	var subject *Breaker
+
+	done, err := subject.Allow()
+	if err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+	done(Failure)
+
+	if got := subject.State(); got != StateClosed {
+		t.Errorf("wanted: %v\n got: %v", StateClosed, got)
+	}
+}
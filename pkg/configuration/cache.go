@@ -0,0 +1,107 @@
+package configuration
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// Cache stores the body of fetched accounts by account ID, so FetchAccount does not invoke the account API
+// while an account is cached. cache.LRU keeps them in memory, other implementations can keep them in a shared
+// store, in that case they are responsible for expiring entries. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the JSON body answered by the account API when accountId was fetched.
+	Get(accountId string) ([]byte, bool)
+	// Set caches body, the JSON answered by the account API when accountId was fetched.
+	Set(accountId string, body []byte)
+	// Delete invalidates accountId, it is invoked when the account is deleted or updated.
+	Delete(accountId string)
+}
+
+// InvalidatingCache is the Cache used by AccountService, it tells whether an account was invalidated while it
+// was being fetched, so a body answered before the account was deleted or updated is not cached again.
+type InvalidatingCache interface {
+	Cache
+	// Generation returns the number of times accountId was invalidated, it is read before fetching it.
+	Generation(accountId string) uint64
+	// SetIfGeneration caches body unless accountId was invalidated since Generation returned generation.
+	SetIfGeneration(accountId string, body []byte, generation uint64)
+}
+
+// CacheStats counts the fetches answered from the cache, hits, and those sent to the account API, misses.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+type nopCache struct{}
+
+func (nopCache) Get(string) ([]byte, bool) { return nil, false }
+
+func (nopCache) Set(string, []byte) {}
+
+func (nopCache) Delete(string) {}
+
+func (nopCache) Generation(string) uint64 { return 0 }
+
+func (nopCache) SetIfGeneration(string, []byte, uint64) {}
+
+// generationBuckets bounds the memory used to track invalidations, account IDs sharing a bucket invalidate
+// each other, which only skips caching a body now and then.
+const generationBuckets = 256
+
+// countingCache decorates the configured Cache to count hits and misses and to track invalidations.
+type countingCache struct {
+	Cache
+	hits   uint64
+	misses uint64
+	// mutex makes invalidating and checking the generation atomic with deleting and setting an entry
+	mutex       sync.Mutex
+	generations [generationBuckets]uint64
+}
+
+func (c *countingCache) Get(accountId string) ([]byte, bool) {
+	body, ok := c.Cache.Get(accountId)
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+
+	return body, ok
+}
+
+func (c *countingCache) stats() CacheStats {
+	return CacheStats{Hits: atomic.LoadUint64(&c.hits), Misses: atomic.LoadUint64(&c.misses)}
+}
+
+func (c *countingCache) Delete(accountId string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generations[generationBucket(accountId)]++
+	c.Cache.Delete(accountId)
+}
+
+func (c *countingCache) Generation(accountId string) uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generations[generationBucket(accountId)]
+}
+
+func (c *countingCache) SetIfGeneration(accountId string, body []byte, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.generations[generationBucket(accountId)] == generation {
+		c.Cache.Set(accountId, body)
+	}
+}
+
+func generationBucket(accountId string) uint32 {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(accountId))
+
+	return hash.Sum32() % generationBuckets
+}
@@ -1,30 +1,158 @@
 package configuration
 
 import (
-	"fmt"
+	"accountapi-lib-form3/pkg/circuitbreaker"
+	"accountapi-lib-form3/pkg/logging"
+	"accountapi-lib-form3/pkg/tracing"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
 )
 
 type Config interface {
 	GetAPIBasePath() string
 	GetHttpClient() *http.Client
+	IsRequestValidationEnabled() bool
+	GetMetricsRecorder() MetricsRecorder
+	GetTracer() tracing.Tracer
+	GetRequestLimiter() RequestLimiter
+	GetCircuitBreaker() *circuitbreaker.Breaker
+	GetCache() InvalidatingCache
+	GetCacheStats() CacheStats
 }
 
 type config struct {
-	apiVersion string
-	host       string
-	port       string
-	httpClient *http.Client
-	verboseLog bool
+	scheme      string
+	apiVersion  string
+	host        string
+	port        string
+	basePath    string
+	rawQuery    string
+	baseURLErr  error
+	httpClient  *http.Client
+	timeout     time.Duration
+	verboseLog  bool
+	retryPolicy *RetryPolicy
+	tls         tlsSettings
+	signer      RequestSigner
+	logger      logging.Logger
+	redactor    *logging.Redactor
+	metrics     MetricsRecorder
+	tracer      tracing.Tracer
+	rateLimit   rateLimitSettings
+	// maxConcurrentRequests is 0 when concurrency is not limited
+	maxConcurrentRequests int
+	limiter               RequestLimiter
+	circuitBreaker        *circuitbreaker.Settings
+	breaker               *circuitbreaker.Breaker
+	fetchCache            Cache
+	cache                 *countingCache
+	// skipValidation is negated, so the zero value keeps client-side validation enabled
+	skipValidation bool
+	// settings are those of the builder when the configuration was built, before the http.Client was wrapped,
+	// so ConfigBuilder.From can derive variants of it
+	settings *config
 }
 
-// defaultScheme can be changed when service consumption has to be through another protocol such as secure http (https)
-const defaultScheme = "http"
+// tlsSettings holds what is needed to build the tls.Config of the default http.Client, the zero value
+// means that no TLS setting was configured.
+type tlsSettings struct {
+	rootCAs            *x509.CertPool
+	clientCertificates []tls.Certificate
+	minVersion         uint16
+	serverName         string
+}
+
+// clone copies the settings, so appending to a slice of the copy does not modify the original.
+func (c *config) clone() config {
+	clone := *c
+	clone.tls.clientCertificates = append([]tls.Certificate(nil), c.tls.clientCertificates...)
+
+	return clone
+}
 
+// GetAPIBasePath returns the URL which the paths of the account API are appended to, without a trailing slash.
+// It may have a query, which has to be kept when a path is appended.
 func (c *config) GetAPIBasePath() string {
-	return fmt.Sprintf("%s://%s:%s/%s", defaultScheme, c.host, c.port, c.apiVersion)
+	return c.apiBaseURL().String()
+}
+
+func (c *config) apiBaseURL() *url.URL {
+	host := strings.TrimSuffix(strings.TrimPrefix(c.host, "["), "]")
+	if c.port != "" {
+		host = net.JoinHostPort(host, c.port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	basePath := path.Join("/", c.basePath, c.apiVersion)
+	if basePath == "/" {
+		basePath = ""
+	}
+
+	return &url.URL{Scheme: c.scheme, Host: host, Path: basePath, RawQuery: c.rawQuery}
 }
 
 func (c *config) GetHttpClient() *http.Client {
 	return c.httpClient
 }
+
+func (c *config) IsRequestValidationEnabled() bool {
+	return !c.skipValidation
+}
+
+// GetMetricsRecorder returns the configured recorder, or one that discards everything.
+func (c *config) GetMetricsRecorder() MetricsRecorder {
+	if c.metrics == nil {
+		return nopMetricsRecorder{}
+	}
+
+	return c.metrics
+}
+
+// GetTracer returns the configured tracer, or one whose spans record nothing.
+func (c *config) GetTracer() tracing.Tracer {
+	if c.tracer == nil {
+		return tracing.NopTracer()
+	}
+
+	return c.tracer
+}
+
+// GetRequestLimiter returns the limiter shared by every operation, or one that never waits.
+func (c *config) GetRequestLimiter() RequestLimiter {
+	if c.limiter == nil {
+		return nopRequestLimiter{}
+	}
+
+	return c.limiter
+}
+
+// GetCircuitBreaker returns the breaker shared by every operation, or nil, which allows every request, when
+// no circuit breaker is configured.
+func (c *config) GetCircuitBreaker() *circuitbreaker.Breaker {
+	return c.breaker
+}
+
+// GetCache returns the cache of fetched accounts, or one that never caches anything.
+func (c *config) GetCache() InvalidatingCache {
+	if c.cache == nil {
+		return nopCache{}
+	}
+
+	return c.cache
+}
+
+// GetCacheStats returns the hits and misses of the cache of fetched accounts since the configuration was built.
+func (c *config) GetCacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+
+	return c.cache.stats()
+}
@@ -1,7 +1,14 @@
 package configuration
 
 import (
+	"accountapi-lib-form3/pkg/circuitbreaker"
+	"accountapi-lib-form3/pkg/logging"
+	"accountapi-lib-form3/pkg/tracing"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -10,8 +17,28 @@ type ConfigBuilder interface {
 	WithAPIVersion(string) ConfigBuilder
 	WithHost(string) ConfigBuilder
 	WithPort(string) ConfigBuilder
+	WithBaseURL(string) ConfigBuilder
+	WithTimeout(time.Duration) ConfigBuilder
 	Verbose() ConfigBuilder
+	WithRetryPolicy(RetryPolicy) ConfigBuilder
+	WithScheme(string) ConfigBuilder
+	WithRootCAs(*x509.CertPool) ConfigBuilder
+	WithClientCertificate(tls.Certificate) ConfigBuilder
+	WithMinTLSVersion(uint16) ConfigBuilder
+	WithServerName(string) ConfigBuilder
+	WithRequestSigner(RequestSigner) ConfigBuilder
+	WithoutRequestValidation() ConfigBuilder
+	WithLogger(logging.Logger) ConfigBuilder
+	WithRedactor(*logging.Redactor) ConfigBuilder
+	WithMetricsRecorder(MetricsRecorder) ConfigBuilder
+	WithTracer(tracing.Tracer) ConfigBuilder
+	WithRateLimit(requestsPerSecond float64, burst int) ConfigBuilder
+	WithMaxConcurrentRequests(int) ConfigBuilder
+	WithCircuitBreaker(circuitbreaker.Settings) ConfigBuilder
+	WithCache(Cache) ConfigBuilder
+	From(Config) ConfigBuilder
 	Build() Config
+	BuildE() (Config, error)
 }
 
 type configBuilderStruct struct {
@@ -19,6 +46,7 @@ type configBuilderStruct struct {
 }
 
 const (
+	defaultScheme     = "http"
 	defaultAPIVersion = "v1"
 	defaultPort       = "80"
 	defaultTimeout    = 4 * time.Second
@@ -31,6 +59,7 @@ const (
 // it is possible to change the default http.Client.
 func NewDefaultConfigBuilder() ConfigBuilder {
 	configBuilder := new(configBuilderStruct)
+	configBuilder.config.scheme = defaultScheme
 	configBuilder.config.port = defaultPort
 	configBuilder.config.apiVersion = defaultAPIVersion
 	configBuilder.config.verboseLog = defaultVerbose
@@ -58,41 +87,317 @@ func (c *configBuilderStruct) WithPort(port string) ConfigBuilder {
 	return c
 }
 
+// WithBaseURL sets the scheme, host, port, path and query of the account API at once, e.g.
+// https://gateway/payments-gw/accountapi/v1, so it can be reached through a gateway exposing it under a path
+// prefix. The API version is expected to be part of the path and the port is the default one of the scheme
+// when it is omitted. WithScheme, WithHost and WithPort invoked afterwards override the corresponding part,
+// whereas WithAPIVersion appends the version to the path. An invalid URL is reported by BuildE.
+func (c *configBuilderStruct) WithBaseURL(baseURL string) ConfigBuilder {
+	parsed, err := parseBaseURL(baseURL)
+	c.config.baseURLErr = err
+	if err != nil {
+		return c
+	}
+
+	c.config.scheme = parsed.Scheme
+	c.config.host = parsed.Hostname()
+	c.config.port = parsed.Port()
+	c.config.basePath = parsed.Path
+	c.config.rawQuery = parsed.RawQuery
+	c.config.apiVersion = ""
+	return c
+}
+
+// parseBaseURL parses the URL given to WithBaseURL, which must be absolute and must not carry credentials
+// or a fragment.
+func parseBaseURL(baseURL string) (*url.URL, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme == "" || parsed.Hostname() == "" {
+		return nil, errors.New("scheme and host are required")
+	}
+	if parsed.User != nil || parsed.Fragment != "" {
+		return nil, errors.New("credentials and fragments are not supported")
+	}
+
+	return parsed, nil
+}
+
+// WithTimeout sets the timeout of the default http.Client, 4 seconds by default. It covers every attempt of
+// a retry policy and it does not apply when a http.Client is given through WithHttpClient.
+func (c *configBuilderStruct) WithTimeout(timeout time.Duration) ConfigBuilder {
+	c.config.timeout = timeout
+	return c
+}
+
 func (c *configBuilderStruct) Verbose() ConfigBuilder {
 	c.config.verboseLog = true
 	return c
 }
 
-// Build returns a new configuration to invoke backend API, it is important to clarify that
-// if Build receives a particular http.Client implementation and verbose logging is enabled,
-// this will modify http.Client.Transport to set verbose logging up. Additionally, if http.Client.Transport
-// is nil, this will assign a http.DefaultTransport.
+// WithRetryPolicy enables retries of requests that failed because of a transient problem. It is worth
+// mentioning that http.Client.Timeout covers every attempt, so it may be necessary to increase it.
+func (c *configBuilderStruct) WithRetryPolicy(policy RetryPolicy) ConfigBuilder {
+	c.config.retryPolicy = &policy
+	return c
+}
+
+// WithScheme allows to consume the account API through another protocol such as secure http (https),
+// keep in mind that the default port is 80, so it may be necessary to invoke WithPort as well.
+func (c *configBuilderStruct) WithScheme(scheme string) ConfigBuilder {
+	c.config.scheme = scheme
+	return c
+}
+
+// WithRootCAs replaces the system root CAs used to verify the account API certificate, LoadRootCAs
+// allows to build the pool from PEM files. It only applies to the default http.Client.
+func (c *configBuilderStruct) WithRootCAs(rootCAs *x509.CertPool) ConfigBuilder {
+	c.config.tls.rootCAs = rootCAs
+	return c
+}
+
+// WithClientCertificate adds a certificate presented to the account API when mutual TLS is required,
+// LoadClientCertificate allows to load it from PEM files. It only applies to the default http.Client.
+func (c *configBuilderStruct) WithClientCertificate(certificate tls.Certificate) ConfigBuilder {
+	c.config.tls.clientCertificates = append(c.config.tls.clientCertificates, certificate)
+	return c
+}
+
+// WithMinTLSVersion sets the minimum TLS version accepted, e.g. tls.VersionTLS13. By default, it is
+// TLS 1.2. It only applies to the default http.Client.
+func (c *configBuilderStruct) WithMinTLSVersion(version uint16) ConfigBuilder {
+	c.config.tls.minVersion = version
+	return c
+}
+
+// WithServerName overrides the name used to verify the account API certificate, it is useful when the
+// host is an IP address or an internal name. It only applies to the default http.Client.
+func (c *configBuilderStruct) WithServerName(serverName string) ConfigBuilder {
+	c.config.tls.serverName = serverName
+	return c
+}
+
+// WithRequestSigner signs every request sent to the account API, e.g. by using signing.NewSigner.
+func (c *configBuilderStruct) WithRequestSigner(signer RequestSigner) ConfigBuilder {
+	c.config.signer = signer
+	return c
+}
+
+// WithoutRequestValidation disables the client-side validation run before creating an account, so
+// requests are sent to the account API as they are.
+func (c *configBuilderStruct) WithoutRequestValidation() ConfigBuilder {
+	c.config.skipValidation = true
+	return c
+}
+
+// WithLogger logs every request with its operation, account ID, status, latency and attempt. When Verbose
+// is enabled as well, headers and bodies are logged at debug level, only if the logger accepts it. Without
+// a logger, Verbose writes everything to the standard output.
+func (c *configBuilderStruct) WithLogger(logger logging.Logger) ConfigBuilder {
+	c.config.logger = logger
+	return c
+}
+
+// WithRedactor replaces the fields and headers masked before they are logged, by default names, IBAN,
+// account number, secondary identification and credential headers are masked, see logging.DefaultRedactor.
+func (c *configBuilderStruct) WithRedactor(redactor *logging.Redactor) ConfigBuilder {
+	c.config.redactor = redactor
+	return c
+}
+
+// WithMetricsRecorder records request counts, latency, status code classes, retries and requests in flight of
+// every operation, e.g. by using metrics.NewPrometheusRecorder.
+func (c *configBuilderStruct) WithMetricsRecorder(recorder MetricsRecorder) ConfigBuilder {
+	c.config.metrics = recorder
+	return c
+}
+
+// WithTracer opens a span around every operation and propagates the trace context carried by the context
+// of the operation through traceparent and tracestate headers, along with an X-Request-ID header. The request
+// ID is taken from the context, see tracing.WithRequestID, or generated. tracing.NopTracer() only propagates
+// headers, e.g. a span context obtained through tracing.Extract.
+func (c *configBuilderStruct) WithTracer(tracer tracing.Tracer) ConfigBuilder {
+	c.config.tracer = tracer
+	return c
+}
+
+// WithRateLimit caps the rate of operations with a token bucket refilled with requestsPerSecond tokens,
+// up to burst. Operations wait for a token before being sent, as long as their context allows it, and the time
+// spent waiting is recorded by the MetricsRecorder. Retries of an operation do not take extra tokens.
+func (c *configBuilderStruct) WithRateLimit(requestsPerSecond float64, burst int) ConfigBuilder {
+	c.config.rateLimit = rateLimitSettings{requestsPerSecond: requestsPerSecond, burst: burst}
+	return c
+}
+
+// WithMaxConcurrentRequests caps the number of operations waiting for the account API at the same time, others
+// wait for a slot, as long as their context allows it.
+func (c *configBuilderStruct) WithMaxConcurrentRequests(maxConcurrentRequests int) ConfigBuilder {
+	c.config.maxConcurrentRequests = maxConcurrentRequests
+	return c
+}
+
+// WithCircuitBreaker stops sending requests once the account API keeps failing, as defined by settings, so
+// operations fail fast with error_handling.ErrCircuitOpen until the cool-down period has elapsed. Transport errors,
+// 429 and 5xx status codes count as failures, whereas requests canceled through their context are not counted.
+// The breaker is shared by every AccountService built from the same configuration.
+func (c *configBuilderStruct) WithCircuitBreaker(settings circuitbreaker.Settings) ConfigBuilder {
+	c.config.circuitBreaker = &settings
+	return c
+}
+
+// WithCache answers FetchAccount from cache while the account is cached, e.g. by using cache.NewLRU. Accounts
+// are invalidated when they are deleted or updated through a client built from this configuration, updates
+// made by other clients are only seen once the entry expires.
+func (c *configBuilderStruct) WithCache(cache Cache) ConfigBuilder {
+	c.config.fetchCache = cache
+	return c
+}
+
+// From replaces every setting of the builder with those of base, so variants of a configuration in use can be
+// derived without modifying it, e.g. NewDefaultConfigBuilder().From(config).WithHost("other").Build(). The rate
+// limit, circuit breaker and cache statistics of the derived configuration start from scratch, whereas the cache
+// store and the given http.Client, if any, are shared. When base was not built by a ConfigBuilder, only its base
+// path, http.Client, request validation, metrics recorder and tracer are taken.
+func (c *configBuilderStruct) From(base Config) ConfigBuilder {
+	if built, ok := base.(*config); ok && built.settings != nil {
+		c.config = built.settings.clone()
+		return c
+	}
+
+	c.WithBaseURL(base.GetAPIBasePath()).
+		WithHttpClient(base.GetHttpClient()).
+		WithMetricsRecorder(base.GetMetricsRecorder()).
+		WithTracer(base.GetTracer())
+	c.config.skipValidation = !base.IsRequestValidationEnabled()
+	return c
+}
+
+// Build returns a new configuration to invoke backend API. The configuration is a snapshot of the builder, so
+// invoking the builder afterwards does not modify it, and it is safe for concurrent use, unlike the builder.
+// A particular http.Client is copied before its Transport is decorated when verbose logging, a logger, a request
+// signer, a retry policy or a tracer is configured, so the given http.Client is never modified. If
+// http.Client.Transport is nil, http.DefaultTransport is decorated.
 //
 // It is important to clarify that a component which uses this library has to pass around the host
 // where the backend API is located.
 func (c *configBuilderStruct) Build() Config {
+	settings := c.config.clone()
+	built := settings.clone()
+	built.settings = &settings
 
-	if c.config.httpClient == nil {
-		c.config.httpClient = NewDefaultHttpClient(defaultTimeout, c.verboseLog)
-	} else {
-		if c.verboseLog {
-			setVerboseLogging(c.httpClient)
+	if built.httpClient == nil {
+		timeout := built.timeout
+		if timeout == 0 {
+			timeout = defaultTimeout
 		}
+		built.httpClient = NewDefaultHttpClientWithTLS(timeout, false, built.tls.build())
+	} else {
+		httpClient := *built.httpClient
+		built.httpClient = &httpClient
+	}
+
+	if built.verboseLog || built.logger != nil {
+		setRequestLogging(built.httpClient, built.logger, built.redactor, built.verboseLog)
+	}
+
+	if built.signer != nil {
+		setRequestSigner(built.httpClient, built.signer)
+	}
+
+	if built.retryPolicy != nil {
+		setRetryPolicy(built.httpClient, *built.retryPolicy, built.GetMetricsRecorder())
+	}
+
+	if built.tracer != nil {
+		setTracing(built.httpClient)
+	}
+
+	built.limiter = buildRequestLimiter(built.rateLimit, built.maxConcurrentRequests)
+
+	if built.circuitBreaker != nil {
+		built.breaker = circuitbreaker.New(*built.circuitBreaker)
+	}
+
+	if built.fetchCache != nil {
+		built.cache = &countingCache{Cache: built.fetchCache}
 	}
 
-	return &c.config
+	return &built
 }
 
-// setVerboseLogging modifies an http.Client by adding a loggingRoundTripper to Transport,
-// it is worth mentioning that this modification is based on the decorator pattern.
+// BuildE works as Build, but it validates the configuration first, so an invalid setting is reported when the
+// application starts instead of on the first request. SettingErrors lists every invalid setting: scheme, host,
+// port, API version, timeout, retry policy, rate limits and TLS settings, which require https and the default
+// http.Client.
+func (c *configBuilderStruct) BuildE() (Config, error) {
+	if err := c.config.validate(); err != nil {
+		return nil, err
+	}
+
+	return c.Build(), nil
+}
+
+// setVerboseLogging modifies an http.Client by adding a verbose loggingRoundTripper to Transport, which
+// writes to the standard output.
 func setVerboseLogging(httpClient *http.Client) {
+	setRequestLogging(httpClient, nil, nil, true)
+}
+
+// setRequestLogging modifies an http.Client by adding a loggingRoundTripper to Transport,
+// it is worth mentioning that this modification is based on the decorator pattern.
+func setRequestLogging(httpClient *http.Client, logger logging.Logger, redactor *logging.Redactor, verbose bool) {
+	transport := httpClient.Transport
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	httpClient.Transport = newLoggingRoundTripper(transport, logger, redactor, verbose)
+}
+
+// setRequestSigner modifies an http.Client by adding a signingRoundTripper to Transport. It is added after
+// verbose logging, so signature headers are logged.
+func setRequestSigner(httpClient *http.Client, signer RequestSigner) {
+	transport := httpClient.Transport
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	httpClient.Transport = &signingRoundTripper{
+		defaultRoundTripper: transport,
+		signer:              signer,
+	}
+}
+
+// setRetryPolicy modifies an http.Client by adding a retryRoundTripper to Transport. It is added after
+// verbose logging, so every attempt is logged.
+func setRetryPolicy(httpClient *http.Client, policy RetryPolicy, recorder MetricsRecorder) {
+	transport := httpClient.Transport
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	httpClient.Transport = &retryRoundTripper{
+		defaultRoundTripper: transport,
+		policy:              policy,
+		metrics:             recorder,
+	}
+}
+
+// setTracing modifies an http.Client by adding a tracingRoundTripper to Transport. It is added after the
+// retry policy, so every attempt shares the same headers.
+func setTracing(httpClient *http.Client) {
 	transport := httpClient.Transport
 
 	if transport == nil {
 		transport = http.DefaultTransport
 	}
 
-	httpClient.Transport = &loggingRoundTripper{
+	httpClient.Transport = &tracingRoundTripper{
 		defaultRoundTripper: transport,
 	}
 }
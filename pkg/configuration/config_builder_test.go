@@ -1,8 +1,16 @@
 package configuration
 
 import (
+	"accountapi-lib-form3/pkg/cache"
+	"accountapi-lib-form3/pkg/circuitbreaker"
+	"accountapi-lib-form3/pkg/logging"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"net/http"
 	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -130,6 +138,7 @@ func TestConfigBuilder_ShouldReturnBasicConfigImplementation(t *testing.T) {
 
 func TestConfigBuilder_ShouldReturnVerboseConfigImplementation(t *testing.T) {
 	want := &config{
+		scheme:     "http",
 		host:       "test",
 		apiVersion: "v2",
 		port:       "8080",
@@ -148,8 +157,8 @@ func TestConfigBuilder_ShouldReturnVerboseConfigImplementation(t *testing.T) {
 	valueType := reflect.ValueOf(subject)
 	got := valueType.Interface().(*config)
 
-	if !reflect.DeepEqual(*got, *want) {
-		t.Errorf("wanted: %#v\n got: %#v", want, got)
+	if !reflect.DeepEqual(*got.settings, *want) {
+		t.Errorf("wanted: %#v\n got: %#v", want, got.settings)
 	}
 
 	transportGot := reflect.TypeOf(got.httpClient.Transport).String()
@@ -157,10 +166,15 @@ func TestConfigBuilder_ShouldReturnVerboseConfigImplementation(t *testing.T) {
 	if transportGot != "*configuration.loggingRoundTripper" {
 		t.Errorf("transport wanted: *configuration.loggingRoundTripper \n transport got: %v", transportGot)
 	}
+
+	if want.httpClient.Transport != nil {
+		t.Errorf("given client transport wanted: nil\n transport got: %T", want.httpClient.Transport)
+	}
 }
 
 func TestConfigBuilder_ShouldReturnNonVerboseConfigImplementation(t *testing.T) {
 	want := &config{
+		scheme:     "http",
 		host:       "test",
 		apiVersion: "v2",
 		port:       "8080",
@@ -178,8 +192,8 @@ func TestConfigBuilder_ShouldReturnNonVerboseConfigImplementation(t *testing.T)
 	valueType := reflect.ValueOf(subject)
 	got := valueType.Interface().(*config)
 
-	if !reflect.DeepEqual(*got, *want) {
-		t.Errorf("wanted: %#v\n got: %#v", want, got)
+	if !reflect.DeepEqual(*got.settings, *want) {
+		t.Errorf("wanted: %#v\n got: %#v", want, got.settings)
 	}
 
 	transportGot := reflect.TypeOf(got.httpClient.Transport).String()
@@ -224,3 +238,240 @@ func TestConfigBuilder_ShouldSetVerboseLoggingNilTransport(t *testing.T) {
 		t.Errorf("wanted: *http.Transport\n got: %v", internalTransportGot)
 	}
 }
+
+func TestConfigBuilder_ShouldSetRetryPolicy(t *testing.T) {
+	want := "*configuration.retryRoundTripper"
+	policy := DefaultRetryPolicy()
+	subject := NewDefaultConfigBuilder().
+		WithHttpClient(&http.Client{Transport: &customTransportFake{}}).
+		WithRetryPolicy(policy).
+		Build()
+
+	got := subject.GetHttpClient().Transport
+	if reflect.TypeOf(got).String() != want {
+		t.Errorf("wanted: %v\n got: %v", want, reflect.TypeOf(got).String())
+	}
+
+	internalTransport := got.(*retryRoundTripper)
+	if !reflect.DeepEqual(internalTransport.policy, policy) {
+		t.Errorf("policy wanted: %#v\n policy got: %#v", policy, internalTransport.policy)
+	}
+}
+
+func TestConfigBuilder_ShouldAssignNewScheme(t *testing.T) {
+	subject := configBuilderStruct{
+		config{
+			scheme: "http",
+		},
+	}
+
+	want := "https"
+	subject.WithScheme(want)
+	got := subject.config.scheme
+
+	if got != want {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+}
+
+func TestConfigBuilder_ShouldAssignTLSSettings(t *testing.T) {
+	rootCAs := x509.NewCertPool()
+	certificate := tls.Certificate{Certificate: [][]byte{{1}}}
+	want := tlsSettings{
+		rootCAs:            rootCAs,
+		clientCertificates: []tls.Certificate{certificate},
+		minVersion:         tls.VersionTLS13,
+		serverName:         "accountapi",
+	}
+
+	subject := configBuilderStruct{}
+	subject.WithRootCAs(rootCAs).
+		WithClientCertificate(certificate).
+		WithMinTLSVersion(tls.VersionTLS13).
+		WithServerName("accountapi")
+	got := subject.config.tls
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %#v\n got: %#v", want, got)
+	}
+}
+
+func TestConfigBuilder_ShouldWireTLSIntoDefaultClient(t *testing.T) {
+	subject := NewDefaultConfigBuilder().
+		WithScheme("https").
+		WithServerName("accountapi").
+		Build()
+
+	transport, ok := subject.GetHttpClient().Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig == nil {
+		t.Fatalf("wanted: transport with TLS config\n got: %v", subject.GetHttpClient().Transport)
+	}
+	if transport.TLSClientConfig.ServerName != "accountapi" {
+		t.Errorf("server name wanted: accountapi\n server name got: %s", transport.TLSClientConfig.ServerName)
+	}
+}
+
+func TestConfigBuilder_ShouldDisableRequestValidation(t *testing.T) {
+	subject := NewDefaultConfigBuilder().
+		WithoutRequestValidation().
+		Build()
+
+	if subject.IsRequestValidationEnabled() {
+		t.Errorf("wanted: false\n got: true")
+	}
+}
+
+func TestConfigBuilder_ShouldSetLogger(t *testing.T) {
+	logger := &loggerFake{}
+	subject := NewDefaultConfigBuilder().
+		WithHttpClient(&http.Client{Transport: &customTransportFake{}}).
+		WithLogger(logger).
+		Build()
+
+	transport, ok := subject.GetHttpClient().Transport.(*loggingRoundTripper)
+	if !ok {
+		t.Fatalf("wanted: *configuration.loggingRoundTripper\n got: %T", subject.GetHttpClient().Transport)
+	}
+	if transport.logger != logger || transport.verbose {
+		t.Errorf("wanted: non verbose transport with the configured logger\n got: %v", transport)
+	}
+}
+
+func TestConfigBuilder_ShouldSetRedactor(t *testing.T) {
+	redactor := logging.NewRedactor(nil, nil)
+	subject := NewDefaultConfigBuilder().
+		Verbose().
+		WithRedactor(redactor).
+		Build()
+
+	transport := subject.GetHttpClient().Transport.(*loggingRoundTripper)
+	if transport.redactor != redactor || !transport.verbose {
+		t.Errorf("wanted: verbose transport with the configured redactor\n got: %v", transport)
+	}
+}
+
+func TestConfigBuilder_ShouldSetCircuitBreaker(t *testing.T) {
+	if got := NewDefaultConfigBuilder().Build().GetCircuitBreaker(); got != nil {
+		t.Fatalf("wanted: no circuit breaker by default\n got: %v", got)
+	}
+
+	subject := NewDefaultConfigBuilder().
+		WithCircuitBreaker(circuitbreaker.Settings{ConsecutiveFailures: 1}).
+		Build()
+
+	breaker := subject.GetCircuitBreaker()
+	if breaker == nil {
+		t.Fatalf("wanted: circuit breaker\n got: nil")
+	}
+	done, _ := breaker.Allow()
+	done(circuitbreaker.Failure)
+	if got := breaker.State(); got != circuitbreaker.StateOpen {
+		t.Errorf("wanted: %v\n got: %v", circuitbreaker.StateOpen, got)
+	}
+}
+
+func TestConfigBuilder_ShouldSetCache(t *testing.T) {
+	if _, ok := NewDefaultConfigBuilder().Build().GetCache().(nopCache); !ok {
+		t.Fatalf("wanted: nopCache by default")
+	}
+
+	subject := NewDefaultConfigBuilder().
+		WithCache(cache.NewLRU(1, time.Minute)).
+		Build()
+
+	subject.GetCache().Set("ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6", []byte("{}"))
+	_, _ = subject.GetCache().Get("ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6")
+	_, _ = subject.GetCache().Get("missing")
+	if got := subject.GetCacheStats(); got != (CacheStats{Hits: 1, Misses: 1}) {
+		t.Errorf("wanted: 1 hit and 1 miss\n got: %+v", got)
+	}
+}
+
+func TestConfigBuilder_ShouldNotModifyBuiltConfig(t *testing.T) {
+	certFile, keyFile := writeCertificateStub(t)
+	certificate, _ := LoadClientCertificate(certFile, keyFile)
+	builder := NewDefaultConfigBuilder().WithScheme("https").WithClientCertificate(certificate)
+	subject := builder.Build().(*config)
+
+	builder.WithHost("other").WithPort("8443").WithClientCertificate(certificate).Verbose()
+
+	if got := subject.GetAPIBasePath(); got != "https://localhost:80/v1" {
+		t.Errorf("wanted: https://localhost:80/v1\n got: %s", got)
+	}
+	if len(subject.tls.clientCertificates) != 1 || subject.verboseLog {
+		t.Errorf("wanted: settings of the builder when it was built\n got: %+v", subject)
+	}
+	if again := builder.Build(); again.GetHttpClient() == subject.GetHttpClient() {
+		t.Errorf("wanted: a new http.Client for every configuration\n got: %p", again.GetHttpClient())
+	}
+}
+
+func TestConfigBuilder_ShouldDeriveConfigFrom(t *testing.T) {
+	client := &http.Client{Transport: &customTransportFake{}}
+	base := NewDefaultConfigBuilder().
+		WithHttpClient(client).
+		WithRetryPolicy(DefaultRetryPolicy()).
+		Verbose().
+		Build()
+
+	subject := NewDefaultConfigBuilder().From(base).WithHost("other").Build()
+
+	if got := subject.GetAPIBasePath(); got != "http://other:80/v1" {
+		t.Errorf("wanted: http://other:80/v1\n got: %s", got)
+	}
+	if got := base.GetAPIBasePath(); got != "http://localhost:80/v1" {
+		t.Errorf("base wanted: http://localhost:80/v1\n got: %s", got)
+	}
+	retry, _ := subject.GetHttpClient().Transport.(*retryRoundTripper)
+	if retry == nil || reflect.TypeOf(retry.defaultRoundTripper.(*loggingRoundTripper).defaultRoundTripper) != reflect.TypeOf(client.Transport) {
+		t.Errorf("wanted: given transport decorated once\n got: %#v", subject.GetHttpClient().Transport)
+	}
+	if _, ok := client.Transport.(*customTransportFake); !ok {
+		t.Errorf("given client transport wanted: *configuration.customTransportFake\n got: %T", client.Transport)
+	}
+}
+
+// configFake is a Config which was not built by a ConfigBuilder.
+type configFake struct {
+	Config
+}
+
+func (configFake) GetAPIBasePath() string { return "https://gateway/accountapi/v1" }
+
+func TestConfigBuilder_ShouldDeriveConfigFromOtherImplementation(t *testing.T) {
+	subject := NewDefaultConfigBuilder().From(configFake{Config: NewDefaultConfigBuilder().WithoutRequestValidation().Build()}).Build()
+
+	if got := subject.GetAPIBasePath(); got != "https://gateway/accountapi/v1" {
+		t.Errorf("wanted: https://gateway/accountapi/v1\n got: %s", got)
+	}
+	if subject.IsRequestValidationEnabled() {
+		t.Errorf("request validation wanted: false\n got: true")
+	}
+}
+
+// TestConfigBuilder_ShouldBuildConfigsSafeForConcurrentUse is meant to be run with -race.
+func TestConfigBuilder_ShouldBuildConfigsSafeForConcurrentUse(t *testing.T) {
+	builder := NewDefaultConfigBuilder().WithHttpClient(&http.Client{}).Verbose().WithRateLimit(1000, 10)
+	base := builder.Build()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			variant := NewDefaultConfigBuilder().From(base).WithPort(strconv.Itoa(8000 + i)).Build()
+			_ = variant.GetAPIBasePath()
+			_ = base.GetAPIBasePath()
+			_ = base.GetHttpClient().Transport
+			if release, err := base.GetRequestLimiter().Acquire(context.Background()); err == nil {
+				release()
+			}
+		}(i)
+	}
+	builder.WithHost("other").Build()
+	wg.Wait()
+
+	if got := base.GetAPIBasePath(); got != "http://localhost:80/v1" {
+		t.Errorf("wanted: http://localhost:80/v1\n got: %s", got)
+	}
+}
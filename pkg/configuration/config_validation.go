@@ -0,0 +1,165 @@
+package configuration
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	msgInvalidScheme      = "must be http or https"
+	msgRequiredSetting    = "is required"
+	msgInvalidHost        = "must be a host name or an IP address"
+	msgInvalidPort        = "must be a number between 1 and 65535"
+	msgInvalidAPIVersion  = "must be a version such as v1"
+	msgNegativeDuration   = "must not be negative"
+	msgTLSWithCustomHttp  = "only applies to the default http.Client, configure TLS in the given http.Client instead"
+	msgTLSWithoutHttps    = "requires the https scheme"
+	msgInvalidTLSVersion  = "must be TLS 1.0, 1.1, 1.2 or 1.3"
+	msgIncompleteCert     = "must have a certificate and a private key"
+	msgInvalidMaxAttempts = "must be at least 1"
+	msgInvalidJitter      = "must be between 0 and 1"
+	msgInvalidRateLimit   = "must not be negative"
+	msgInvalidBaseURLFmt  = "must be a valid URL: %v"
+)
+
+var (
+	hostPattern       = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+	apiVersionPattern = regexp.MustCompile(`^v[0-9]+(\.[0-9]+)*$`)
+)
+
+// SettingError describes why a setting of the configuration is invalid, Setting is named after the builder
+// method that sets it, e.g. port.
+type SettingError struct {
+	Setting string
+	Message string
+}
+
+func (s SettingError) Error() string {
+	return s.Setting + ": " + s.Message
+}
+
+// SettingErrors is the list of invalid settings returned by BuildE, it can be obtained with errors.As.
+type SettingErrors []SettingError
+
+func (s SettingErrors) Error() string {
+	messages := make([]string, 0, len(s))
+	for _, settingErr := range s {
+		messages = append(messages, settingErr.Error())
+	}
+
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// orNil avoids returning a non-nil error interface holding an empty list
+func (s SettingErrors) orNil() error {
+	if len(s) == 0 {
+		return nil
+	}
+
+	return s
+}
+
+func (s *SettingErrors) add(setting string, message string) {
+	*s = append(*s, SettingError{Setting: setting, Message: message})
+}
+
+// validate checks every setting, so the first request does not fail because of a typo in the configuration.
+func (c *config) validate() error {
+	var errs SettingErrors
+
+	c.validateBaseURL(&errs)
+	c.validateTLS(&errs)
+
+	if c.timeout < 0 {
+		errs.add("timeout", msgNegativeDuration)
+	}
+
+	if c.retryPolicy != nil {
+		if c.retryPolicy.MaxAttempts < 1 {
+			errs.add("retryPolicy.MaxAttempts", msgInvalidMaxAttempts)
+		}
+		if c.retryPolicy.BaseDelay < 0 {
+			errs.add("retryPolicy.BaseDelay", msgNegativeDuration)
+		}
+		if c.retryPolicy.MaxDelay < 0 {
+			errs.add("retryPolicy.MaxDelay", msgNegativeDuration)
+		}
+		if c.retryPolicy.Jitter < 0 || c.retryPolicy.Jitter > 1 {
+			errs.add("retryPolicy.Jitter", msgInvalidJitter)
+		}
+	}
+
+	if c.rateLimit.requestsPerSecond < 0 || c.rateLimit.burst < 0 {
+		errs.add("rateLimit", msgInvalidRateLimit)
+	}
+	if c.maxConcurrentRequests < 0 {
+		errs.add("maxConcurrentRequests", msgInvalidRateLimit)
+	}
+
+	return errs.orNil()
+}
+
+func (c *config) validateBaseURL(errs *SettingErrors) {
+	if c.scheme != "http" && c.scheme != "https" {
+		errs.add("scheme", msgInvalidScheme)
+	}
+
+	if c.baseURLErr != nil {
+		errs.add("baseURL", fmt.Sprintf(msgInvalidBaseURLFmt, c.baseURLErr))
+	}
+
+	host := strings.TrimSuffix(strings.TrimPrefix(c.host, "["), "]")
+	switch {
+	case host == "":
+		errs.add("host", msgRequiredSetting)
+	case net.ParseIP(host) == nil && !hostPattern.MatchString(host):
+		errs.add("host", msgInvalidHost)
+	}
+
+	// an empty port is the default one of the scheme
+	if port, err := strconv.Atoi(c.port); c.port != "" && (err != nil || port < 1 || port > 65535) {
+		errs.add("port", msgInvalidPort)
+	}
+
+	// the path of a base URL already has the API version
+	if (c.apiVersion != "" || c.basePath == "") && !apiVersionPattern.MatchString(c.apiVersion) {
+		errs.add("apiVersion", msgInvalidAPIVersion)
+	}
+
+	if len(*errs) == 0 {
+		if _, err := url.Parse(c.GetAPIBasePath()); err != nil {
+			errs.add("baseURL", fmt.Sprintf(msgInvalidBaseURLFmt, err))
+		}
+	}
+}
+
+func (c *config) validateTLS(errs *SettingErrors) {
+	if c.tls.build() == nil {
+		return
+	}
+
+	if c.httpClient != nil {
+		errs.add("tls", msgTLSWithCustomHttp)
+	}
+	if c.scheme != "https" {
+		errs.add("tls", msgTLSWithoutHttps)
+	}
+
+	knownVersion := c.tls.minVersion == 0
+	for _, version := range tlsVersions {
+		knownVersion = knownVersion || version == c.tls.minVersion
+	}
+	if !knownVersion {
+		errs.add("minTLSVersion", msgInvalidTLSVersion)
+	}
+
+	for i, certificate := range c.tls.clientCertificates {
+		if len(certificate.Certificate) == 0 || certificate.PrivateKey == nil {
+			errs.add(fmt.Sprintf("clientCertificate[%d]", i), msgIncompleteCert)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package configuration
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigBuilder_ShouldBuildValidConfiguration(t *testing.T) {
+	subject, err := NewDefaultConfigBuilder().
+		WithHost("accountapi.internal").
+		WithPort("8080").
+		WithTimeout(10 * time.Second).
+		WithRetryPolicy(DefaultRetryPolicy()).
+		BuildE()
+
+	if err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+	if got := subject.GetAPIBasePath(); got != "http://accountapi.internal:8080/v1" {
+		t.Errorf("wanted: http://accountapi.internal:8080/v1\n got: %s", got)
+	}
+}
+
+func TestConfigBuilder_ShouldReportEveryInvalidSetting(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.MaxAttempts, policy.Jitter = 0, 2
+
+	subject, err := NewDefaultConfigBuilder().
+		WithScheme("ftp").
+		WithHost("").
+		WithPort("80 ").
+		WithAPIVersion("/v1").
+		WithTimeout(-time.Second).
+		WithRetryPolicy(policy).
+		WithMaxConcurrentRequests(-1).
+		BuildE()
+
+	var settingErrs SettingErrors
+	if subject != nil || !errors.As(err, &settingErrs) {
+		t.Fatalf("wanted: SettingErrors\n got: %v %v", subject, err)
+	}
+
+	var got []string
+	for _, settingErr := range settingErrs {
+		got = append(got, settingErr.Setting)
+	}
+	want := []string{"scheme", "host", "port", "apiVersion", "timeout", "retryPolicy.MaxAttempts", "retryPolicy.Jitter", "maxConcurrentRequests"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %v\n got: %v", want, got)
+	}
+}
+
+func TestConfigBuilder_ShouldValidateHostAndPort(t *testing.T) {
+	dataTable := []struct {
+		testName string
+		host     string
+		port     string
+		valid    bool
+	}{
+		{"name", "localhost", "80", true},
+		{"ipv4", "10.0.0.1", "443", true},
+		{"ipv6", "[::1]", "65535", true},
+		{"ipv6WithoutBrackets", "::1", "80", true},
+		{"defaultPort", "localhost", "", true},
+		{"hostWithPath", "accountapi/v1", "80", false},
+		{"hostWithSpace", "account api", "80", false},
+		{"portZero", "localhost", "0", false},
+		{"portTooHigh", "localhost", "65536", false},
+		{"portNotNumber", "localhost", "http", false},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			_, err := NewDefaultConfigBuilder().WithHost(v.host).WithPort(v.port).BuildE()
+
+			if (err == nil) != v.valid {
+				t.Errorf("valid wanted: %v\n got: %v", v.valid, err)
+			}
+		})
+	}
+}
+
+func TestConfigBuilder_ShouldValidateTLSSettings(t *testing.T) {
+	certFile, keyFile := writeCertificateStub(t)
+	certificate, _ := LoadClientCertificate(certFile, keyFile)
+
+	_, err := NewDefaultConfigBuilder().
+		WithScheme("https").
+		WithPort("443").
+		WithClientCertificate(certificate).
+		WithMinTLSVersion(tls.VersionTLS13).
+		BuildE()
+	if err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+
+	_, err = NewDefaultConfigBuilder().
+		WithHttpClient(&http.Client{}).
+		WithClientCertificate(tls.Certificate{}).
+		WithMinTLSVersion(0x0200).
+		BuildE()
+
+	var settingErrs SettingErrors
+	if !errors.As(err, &settingErrs) || len(settingErrs) != 4 {
+		t.Errorf("wanted: custom client, http scheme, version and certificate errors\n got: %v", err)
+	}
+}
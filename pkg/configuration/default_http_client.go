@@ -1,22 +1,33 @@
 package configuration
 
 import (
+	"crypto/tls"
 	"net/http"
 	"time"
 )
 
 func NewDefaultHttpClient(timeout time.Duration, verboseLog bool) *http.Client {
+	return NewDefaultHttpClientWithTLS(timeout, verboseLog, nil)
+}
+
+// NewDefaultHttpClientWithTLS works as NewDefaultHttpClient, but when tlsConfig is not nil, the http.DefaultTransport
+// is cloned in order to use tlsConfig without modifying the transport shared by the whole process.
+func NewDefaultHttpClientWithTLS(timeout time.Duration, verboseLog bool, tlsConfig *tls.Config) *http.Client {
 	customTransport := http.DefaultTransport
 
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		customTransport = transport
+	}
+
 	httpClient := &http.Client{
 		Timeout:   timeout,
 		Transport: customTransport,
 	}
 
 	if verboseLog {
-		httpClient.Transport = &loggingRoundTripper{
-			defaultRoundTripper: customTransport,
-		}
+		httpClient.Transport = newLoggingRoundTripper(customTransport, nil, nil, true)
 	}
 
 	return httpClient
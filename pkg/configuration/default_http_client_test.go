@@ -1,6 +1,8 @@
 package configuration
 
 import (
+	"crypto/tls"
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -35,3 +37,19 @@ func TestDefaultClient_ShouldReturnCustomTimeout(t *testing.T) {
 		t.Errorf("wanted: %v\n got: %v", want, got)
 	}
 }
+
+func TestDefaultClient_ShouldCloneDefaultTransportWithTLS(t *testing.T) {
+	want := &tls.Config{ServerName: "test", MinVersion: tls.VersionTLS12}
+	subject := NewDefaultHttpClientWithTLS(4*time.Second, false, want)
+
+	got, ok := subject.Transport.(*http.Transport)
+	if !ok || got == http.DefaultTransport {
+		t.Fatalf("wanted: a clone of http.DefaultTransport\n got: %v", subject.Transport)
+	}
+	if got.TLSClientConfig != want {
+		t.Errorf("wanted: %v\n got: %v", want, got.TLSClientConfig)
+	}
+	if http.DefaultTransport.(*http.Transport).TLSClientConfig == want {
+		t.Errorf("wanted: http.DefaultTransport not modified")
+	}
+}
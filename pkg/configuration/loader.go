@@ -0,0 +1,406 @@
+package configuration
+
+import (
+	"accountapi-lib-form3/pkg/signing"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys of the configuration schema shared by FromFile and FromEnv. In a JSON file, keys with a dot are nested
+// objects, e.g. {"tls": {"server_name": "accountapi"}}, whereas environment variables are named after the key
+// in upper case with underscores and the prefix, e.g. ACCOUNTAPI_TLS_SERVER_NAME. Lists are JSON arrays or
+// comma-separated values.
+const (
+	// KeyBaseURL is the URL of the account API including its version, e.g. https://gateway/accountapi/v1, see
+	// WithBaseURL.
+	KeyBaseURL = "base_url"
+	KeyScheme  = "scheme"
+	KeyHost    = "host"
+	KeyPort    = "port"
+	// KeyAPIVersion is the version of the account API, e.g. v1.
+	KeyAPIVersion = "api_version"
+	// KeyTimeout is the timeout of the default http.Client as a Go duration, e.g. 4s.
+	KeyTimeout = "timeout"
+	KeyVerbose = "verbose"
+	// KeyTLSRootCAFiles is the list of PEM files of the root CAs, see LoadRootCAs.
+	KeyTLSRootCAFiles = "tls.root_ca_files"
+	// KeyTLSClientCertFile and KeyTLSClientKeyFile are the PEM files of the client certificate, see
+	// LoadClientCertificate. Both must be set.
+	KeyTLSClientCertFile = "tls.client_cert_file"
+	KeyTLSClientKeyFile  = "tls.client_key_file"
+	// KeyTLSMinVersion is the minimum TLS version, 1.0, 1.1, 1.2 or 1.3.
+	KeyTLSMinVersion = "tls.min_version"
+	KeyTLSServerName = "tls.server_name"
+	// Retry keys override DefaultRetryPolicy, setting any of them enables retries. Delays are Go durations.
+	KeyRetryMaxAttempts        = "retry.max_attempts"
+	KeyRetryBaseDelay          = "retry.base_delay"
+	KeyRetryMaxDelay           = "retry.max_delay"
+	KeyRetryJitter             = "retry.jitter"
+	KeyRetryRetryNonIdempotent = "retry.retry_non_idempotent"
+	// KeySigningKeyID and KeySigningPrivateKeyFile configure a signing.Signer, both must be set. The private
+	// key is a PEM file, see signing.LoadPrivateKey.
+	KeySigningKeyID          = "signing.key_id"
+	KeySigningPrivateKeyFile = "signing.private_key_file"
+	// KeySigningHeader is the header carrying the signature, signature (by default) or authorization.
+	KeySigningHeader = "signing.header"
+)
+
+var schemaKeys = []string{
+	KeyBaseURL, KeyScheme, KeyHost, KeyPort, KeyAPIVersion, KeyTimeout, KeyVerbose,
+	KeyTLSRootCAFiles, KeyTLSClientCertFile, KeyTLSClientKeyFile, KeyTLSMinVersion, KeyTLSServerName,
+	KeyRetryMaxAttempts, KeyRetryBaseDelay, KeyRetryMaxDelay, KeyRetryJitter, KeyRetryRetryNonIdempotent,
+	KeySigningKeyID, KeySigningPrivateKeyFile, KeySigningHeader,
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// environ returns the environment variables as key=value, it is os.Environ outside tests.
+var environ = os.Environ
+
+// UnknownKeysError lists the keys found in a file, as dotted paths, or in the environment, as variable names,
+// which are not part of the schema. It is returned along with a builder that ignored them, so the caller
+// decides whether a typo is fatal.
+type UnknownKeysError struct {
+	Keys []string
+}
+
+func (u *UnknownKeysError) Error() string {
+	return "unknown configuration keys: " + strings.Join(u.Keys, ", ")
+}
+
+// FromFile returns a builder populated from the JSON file at path on top of the defaults.
+func FromFile(path string) (ConfigBuilder, error) {
+	return Load(path, "")
+}
+
+// FromEnv returns a builder populated from the environment variables named after prefix on top of the
+// defaults, e.g. ACCOUNTAPI_HOST for the prefix ACCOUNTAPI.
+func FromEnv(prefix string) (ConfigBuilder, error) {
+	return Load("", prefix)
+}
+
+// Load returns a builder populated from the defaults, then the JSON file at path, then the environment
+// variables named after envPrefix, each one overriding the previous one. An empty path or envPrefix skips that
+// source. The builder can be customised further before invoking Build, overriding every source. When the only
+// problem is keys outside the schema, both the builder and an *UnknownKeysError are returned.
+func Load(path string, envPrefix string) (ConfigBuilder, error) {
+	values := map[string]string{}
+	var unknown []string
+
+	if path != "" {
+		fileValues, fileUnknown, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		mergeLayer(values, fileValues)
+		unknown = append(unknown, fileUnknown...)
+	}
+
+	if envPrefix != "" {
+		envValues, envUnknown := readEnv(envPrefix)
+		mergeLayer(values, envValues)
+		unknown = append(unknown, envUnknown...)
+	}
+
+	builder := NewDefaultConfigBuilder()
+	if err := apply(builder, values); err != nil {
+		return nil, err
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return builder, &UnknownKeysError{Keys: unknown}
+	}
+
+	return builder, nil
+}
+
+// baseURLParts are the keys overriding a part of the base URL.
+var baseURLParts = []string{KeyScheme, KeyHost, KeyPort, KeyAPIVersion}
+
+// mergeLayer sets the values of layer on top of values. A base URL replaces the parts of the base URL set by the
+// layers below, otherwise apply would override it with them.
+func mergeLayer(values map[string]string, layer map[string]string) {
+	if _, ok := layer[KeyBaseURL]; ok {
+		for _, key := range baseURLParts {
+			delete(values, key)
+		}
+	}
+
+	for key, value := range layer {
+		values[key] = value
+	}
+}
+
+// readFile flattens the JSON object at path into dotted keys, lists are joined with commas.
+func readFile(path string) (map[string]string, []string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed reading configuration file: %w", err)
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, nil, fmt.Errorf("failed decoding configuration file %s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, key := range schemaKeys {
+		known[key] = true
+	}
+
+	values := map[string]string{}
+	var unknown []string
+	var flatten func(prefix string, object map[string]interface{})
+	flatten = func(prefix string, object map[string]interface{}) {
+		for name, value := range object {
+			key := prefix + name
+			if nested, ok := value.(map[string]interface{}); ok && !known[key] {
+				flatten(key+".", nested)
+				continue
+			}
+			if !known[key] {
+				unknown = append(unknown, key)
+				continue
+			}
+			values[key] = scalarString(value)
+		}
+	}
+	flatten("", document)
+
+	return values, unknown, nil
+}
+
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, scalarString(item))
+		}
+		return strings.Join(items, ",")
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// readEnv returns the values of the environment variables named after prefix and the names of the variables
+// with the prefix that are not part of the schema.
+func readEnv(prefix string) (map[string]string, []string) {
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	names := map[string]string{}
+	for _, key := range schemaKeys {
+		names[prefix+strings.ToUpper(strings.NewReplacer(".", "_").Replace(key))] = key
+	}
+
+	values := map[string]string{}
+	var unknown []string
+	for _, variable := range environ() {
+		name, value := variable, ""
+		if i := strings.Index(variable, "="); i >= 0 {
+			name, value = variable[:i], variable[i+1:]
+		}
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		if key, ok := names[name]; ok {
+			values[key] = value
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+
+	return values, unknown
+}
+
+// apply invokes the builder methods matching values, the base URL is applied first, so scheme, host and port
+// override its parts and the API version is appended to its path.
+func apply(builder ConfigBuilder, values map[string]string) error {
+	if value, ok := values[KeyBaseURL]; ok {
+		if err := applyBaseURL(builder, value); err != nil {
+			return err
+		}
+	}
+
+	for key, with := range map[string]func(string) ConfigBuilder{
+		KeyScheme:     builder.WithScheme,
+		KeyHost:       builder.WithHost,
+		KeyPort:       builder.WithPort,
+		KeyAPIVersion: builder.WithAPIVersion,
+	} {
+		if value, ok := values[key]; ok {
+			with(value)
+		}
+	}
+
+	if value, ok := values[KeyTimeout]; ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return invalidValue(KeyTimeout, value, err)
+		}
+		builder.WithTimeout(timeout)
+	}
+
+	if value, ok := values[KeyVerbose]; ok {
+		verbose, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalidValue(KeyVerbose, value, err)
+		}
+		if verbose {
+			builder.Verbose()
+		}
+	}
+
+	if err := applyTLS(builder, values); err != nil {
+		return err
+	}
+
+	if err := applyRetryPolicy(builder, values); err != nil {
+		return err
+	}
+
+	return applySigner(builder, values)
+}
+
+func applyBaseURL(builder ConfigBuilder, value string) error {
+	if _, err := parseBaseURL(value); err != nil {
+		return invalidValue(KeyBaseURL, value, err)
+	}
+
+	builder.WithBaseURL(value)
+	return nil
+}
+
+func applyTLS(builder ConfigBuilder, values map[string]string) error {
+	if value, ok := values[KeyTLSRootCAFiles]; ok && value != "" {
+		rootCAs, err := LoadRootCAs(strings.Split(value, ",")...)
+		if err != nil {
+			return invalidValue(KeyTLSRootCAFiles, value, err)
+		}
+		builder.WithRootCAs(rootCAs)
+	}
+
+	certFile, keyFile := values[KeyTLSClientCertFile], values[KeyTLSClientKeyFile]
+	if certFile != "" || keyFile != "" {
+		certificate, err := LoadClientCertificate(certFile, keyFile)
+		if err != nil {
+			return invalidValue(KeyTLSClientCertFile, certFile, err)
+		}
+		builder.WithClientCertificate(certificate)
+	}
+
+	if value, ok := values[KeyTLSMinVersion]; ok {
+		version, known := tlsVersions[value]
+		if !known {
+			return invalidValue(KeyTLSMinVersion, value, fmt.Errorf("supported versions are 1.0, 1.1, 1.2 and 1.3"))
+		}
+		builder.WithMinTLSVersion(version)
+	}
+
+	if value, ok := values[KeyTLSServerName]; ok {
+		builder.WithServerName(value)
+	}
+
+	return nil
+}
+
+func applyRetryPolicy(builder ConfigBuilder, values map[string]string) error {
+	policy := DefaultRetryPolicy()
+	enabled := false
+
+	if value, ok := values[KeyRetryMaxAttempts]; ok {
+		maxAttempts, err := strconv.Atoi(value)
+		if err != nil {
+			return invalidValue(KeyRetryMaxAttempts, value, err)
+		}
+		policy.MaxAttempts, enabled = maxAttempts, true
+	}
+
+	for key, delay := range map[string]*time.Duration{KeyRetryBaseDelay: &policy.BaseDelay, KeyRetryMaxDelay: &policy.MaxDelay} {
+		if value, ok := values[key]; ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return invalidValue(key, value, err)
+			}
+			*delay, enabled = parsed, true
+		}
+	}
+
+	if value, ok := values[KeyRetryJitter]; ok {
+		jitter, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return invalidValue(KeyRetryJitter, value, err)
+		}
+		policy.Jitter, enabled = jitter, true
+	}
+
+	if value, ok := values[KeyRetryRetryNonIdempotent]; ok {
+		retryNonIdempotent, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalidValue(KeyRetryRetryNonIdempotent, value, err)
+		}
+		policy.RetryNonIdempotent, enabled = retryNonIdempotent, true
+	}
+
+	if enabled {
+		builder.WithRetryPolicy(policy)
+	}
+
+	return nil
+}
+
+func applySigner(builder ConfigBuilder, values map[string]string) error {
+	keyID, keyFile := values[KeySigningKeyID], values[KeySigningPrivateKeyFile]
+	if keyID == "" && keyFile == "" {
+		return nil
+	}
+	if keyID == "" || keyFile == "" {
+		return fmt.Errorf("%s and %s must be set together", KeySigningKeyID, KeySigningPrivateKeyFile)
+	}
+
+	headerMode := signing.SignatureHeader
+	switch header := values[KeySigningHeader]; header {
+	case "", "signature":
+	case "authorization":
+		headerMode = signing.AuthorizationHeader
+	default:
+		return invalidValue(KeySigningHeader, header, fmt.Errorf("supported headers are signature and authorization"))
+	}
+
+	key, err := signing.LoadPrivateKey(keyFile)
+	if err != nil {
+		return invalidValue(KeySigningPrivateKeyFile, keyFile, err)
+	}
+
+	signer, err := signing.NewSigner(keyID, key, headerMode)
+	if err != nil {
+		return invalidValue(KeySigningPrivateKeyFile, keyFile, err)
+	}
+	builder.WithRequestSigner(signer)
+
+	return nil
+}
+
+func invalidValue(key string, value string, err error) error {
+	return fmt.Errorf("invalid configuration value %q for %s: %w", value, key, err)
+}
@@ -0,0 +1,166 @@
+package configuration
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeFileStub writes content to a file of a temporary directory and returns its path.
+func writeFileStub(t *testing.T, name string, content []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	_ = ioutil.WriteFile(path, content, 0600)
+
+	return path
+}
+
+// setEnvironStub replaces the environment seen by the loader until the test finishes.
+func setEnvironStub(t *testing.T, variables ...string) {
+	original := environ
+	environ = func() []string { return variables }
+	t.Cleanup(func() { environ = original })
+}
+
+func TestLoad_ShouldLayerDefaultsFileAndEnv(t *testing.T) {
+	path := writeFileStub(t, "config.json", []byte(`{
+		"base_url": "https://accountapi/v2",
+		"timeout": "10s",
+		"verbose": true,
+		"retry": {"max_attempts": 5, "base_delay": "50ms"}
+	}`))
+	setEnvironStub(t, "ACCOUNTAPI_PORT=8443", "ACCOUNTAPI_VERBOSE=false", "OTHER_HOST=ignored")
+
+	builder, err := Load(path, "ACCOUNTAPI")
+	if err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+	subject := builder.Build().(*config)
+
+	if got := subject.GetAPIBasePath(); got != "https://accountapi:8443/v2" {
+		t.Errorf("base path wanted: https://accountapi:8443/v2\n got: %s", got)
+	}
+	if subject.GetHttpClient().Timeout != 10*time.Second {
+		t.Errorf("timeout wanted: 10s\n got: %v", subject.GetHttpClient().Timeout)
+	}
+	if subject.verboseLog {
+		t.Errorf("verbose wanted: false, the environment overrides the file\n got: true")
+	}
+	want := DefaultRetryPolicy()
+	want.MaxAttempts, want.BaseDelay = 5, 50*time.Millisecond
+	if !reflect.DeepEqual(*subject.retryPolicy, want) {
+		t.Errorf("retry policy wanted: %v\n got: %v", want, *subject.retryPolicy)
+	}
+}
+
+func TestLoad_ShouldLetBuilderCallsOverrideSources(t *testing.T) {
+	setEnvironStub(t, "ACCOUNTAPI_HOST=from-env")
+
+	builder, _ := FromEnv("ACCOUNTAPI")
+	subject := builder.WithHost("from-code").Build()
+
+	if got := subject.GetAPIBasePath(); got != "http://from-code:80/v1" {
+		t.Errorf("wanted: http://from-code:80/v1\n got: %s", got)
+	}
+}
+
+func TestLoad_ShouldLetEnvBaseURLReplaceFileParts(t *testing.T) {
+	path := writeFileStub(t, "config.json", []byte(`{"host": "filehost", "port": "8080", "api_version": "v1"}`))
+	setEnvironStub(t, "ACCOUNTAPI_BASE_URL=https://envgw/accountapi/v2")
+
+	builder, err := Load(path, "ACCOUNTAPI")
+	if err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+
+	if got := builder.Build().GetAPIBasePath(); got != "https://envgw/accountapi/v2" {
+		t.Errorf("wanted: https://envgw/accountapi/v2\n got: %s", got)
+	}
+}
+
+func TestLoad_ShouldReportUnknownKeys(t *testing.T) {
+	path := writeFileStub(t, "config.json", []byte(`{"host": "accountapi", "tls": {"server_nam": "x"}, "timeot": "1s"}`))
+	setEnvironStub(t, "ACCOUNTAPI_PORTT=80")
+
+	builder, err := Load(path, "ACCOUNTAPI")
+
+	var unknown *UnknownKeysError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("wanted: *UnknownKeysError\n got: %v", err)
+	}
+	if want := []string{"ACCOUNTAPI_PORTT", "timeot", "tls.server_nam"}; !reflect.DeepEqual(unknown.Keys, want) {
+		t.Errorf("wanted: %v\n got: %v", want, unknown.Keys)
+	}
+	if got := builder.Build().GetAPIBasePath(); got != "http://accountapi:80/v1" {
+		t.Errorf("wanted: known keys applied\n got: %s", got)
+	}
+}
+
+func TestLoad_ShouldLoadTLSAndSigningMaterial(t *testing.T) {
+	certFile, keyFile := writeCertificateStub(t)
+	_, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	signingKeyFile := writeFileStub(t, "signing.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	setEnvironStub(t,
+		"ACCOUNTAPI_TLS_ROOT_CA_FILES="+certFile,
+		"ACCOUNTAPI_TLS_CLIENT_CERT_FILE="+certFile,
+		"ACCOUNTAPI_TLS_CLIENT_KEY_FILE="+keyFile,
+		"ACCOUNTAPI_TLS_MIN_VERSION=1.3",
+		"ACCOUNTAPI_SIGNING_KEY_ID=key-1",
+		"ACCOUNTAPI_SIGNING_PRIVATE_KEY_FILE="+signingKeyFile,
+	)
+
+	builder, err := FromEnv("ACCOUNTAPI")
+	if err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+	subject := builder.Build().(*config)
+
+	if subject.tls.rootCAs == nil || len(subject.tls.clientCertificates) != 1 || subject.tls.minVersion != tls.VersionTLS13 {
+		t.Errorf("wanted: TLS material loaded\n got: %+v", subject.tls)
+	}
+	if subject.signer == nil {
+		t.Errorf("wanted: request signer\n got: nil")
+	}
+}
+
+func TestLoad_ShouldFailOnInvalidValues(t *testing.T) {
+	dataTable := []struct {
+		testName string
+		variable string
+	}{
+		{"timeout", "ACCOUNTAPI_TIMEOUT=10"},
+		{"verbose", "ACCOUNTAPI_VERBOSE=maybe"},
+		{"baseURL", "ACCOUNTAPI_BASE_URL=accountapi"},
+		{"minVersion", "ACCOUNTAPI_TLS_MIN_VERSION=1.4"},
+		{"maxAttempts", "ACCOUNTAPI_RETRY_MAX_ATTEMPTS=three"},
+		{"missingCertificate", "ACCOUNTAPI_TLS_CLIENT_KEY_FILE=key.pem"},
+		{"missingSigningKey", "ACCOUNTAPI_SIGNING_KEY_ID=key-1"},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			setEnvironStub(t, v.variable)
+
+			if builder, err := FromEnv("ACCOUNTAPI"); err == nil || builder != nil {
+				t.Errorf("wanted: error\n got: %v %v", builder, err)
+			}
+		})
+	}
+}
+
+func TestLoad_ShouldFailOnInvalidFile(t *testing.T) {
+	if _, err := FromFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("missing file wanted: error\n got: nil")
+	}
+	if _, err := FromFile(writeFileStub(t, "config.json", []byte(`{"host":`))); err == nil {
+		t.Errorf("invalid JSON wanted: error\n got: nil")
+	}
+}
@@ -1,42 +1,143 @@
 package configuration
 
 import (
+	"accountapi-lib-form3/pkg/logging"
 	"bytes"
-	"fmt"
-	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
+	"os"
 	"time"
 )
 
 type loggingRoundTripper struct {
 	defaultRoundTripper http.RoundTripper
+	// logger receives the entries, when it is nil, they are written to the standard output
+	logger logging.Logger
+	// verbose adds a debug entry with headers and bodies of request and response
+	verbose bool
+	// redactor masks sensitive data before it is logged, when it is nil, logging.DefaultRedactor is used
+	redactor *logging.Redactor
 }
 
-// RoundTrip prints information related to request and response. It is important to make sure to use
-// this method to debug purposes as it reduces performance significantly.
+// RoundTrip logs every request with the fields found in its context, e.g. operation, account ID and attempt,
+// along with status and latency. When verbose is enabled and the logger accepts debug entries, headers and
+// bodies are logged as well. It is important to make sure to use verbose mode to debug purposes as it
+// reduces performance significantly.
+//
+// Sensitive data in URLs, headers and bodies is masked by the redactor before it is logged.
 func (l *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := l.getLogger()
+	redactor := l.getRedactor()
 
+	start := time.Now()
 	res, err := l.defaultRoundTripper.RoundTrip(req)
+	latency := time.Since(start)
 
-	fmt.Printf("[%s] Method: %s\n", time.Now().Format(time.RFC3339), req.Method)
-	fmt.Printf("[%s] URL: %s\n", time.Now().Format(time.RFC3339), req.URL.String())
-	fmt.Printf("[%s] Request Headers: %s\n", time.Now().Format(time.RFC3339), req.Header)
+	if err != nil {
+		if logger.Enabled(logging.LevelError) {
+			logger.Log(logging.LevelError, "request failed", requestFields(req, redactor,
+				logging.F(logging.FieldLatency, latency.Milliseconds()),
+				logging.F(logging.FieldError, err),
+			)...)
+		}
+		return res, err
+	}
 
-	if req.Method == http.MethodPost || req.Method == http.MethodPut {
-		getBody := req.GetBody
-		copyBody, _ := getBody()
-		bodyBytes, _ := ioutil.ReadAll(copyBody)
-		fmt.Printf("[%s] Request Body: %s\n", time.Now().Format(time.RFC3339), bodyBytes)
+	if logger.Enabled(logging.LevelInfo) {
+		logger.Log(logging.LevelInfo, "request completed", requestFields(req, redactor,
+			logging.F(logging.FieldStatus, res.StatusCode),
+			logging.F(logging.FieldLatency, latency.Milliseconds()),
+		)...)
 	}
 
-	if err == nil {
-		fmt.Printf("[%s] StatusCode: %s\n", time.Now().Format(time.RFC3339), res.Status)
-		fmt.Printf("[%s] Response Headers: %s\n", time.Now().Format(time.RFC3339), res.Header)
-		bodyBytes, _ := io.ReadAll(res.Body)
-		fmt.Printf("[%s] Response Body: %s\n", time.Now().Format(time.RFC3339), string(bodyBytes))
-		res.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+	if l.verbose && logger.Enabled(logging.LevelDebug) {
+		fields := requestFields(req, redactor, details(req, res, redactor)...)
+		logger.Log(logging.LevelDebug, "request details", fields...)
 	}
 
 	return res, err
 }
+
+// newLoggingRoundTripper decorates transport, a nil logger is replaced by defaultLogger once, instead of on
+// every request.
+func newLoggingRoundTripper(
+	transport http.RoundTripper, logger logging.Logger, redactor *logging.Redactor, verbose bool,
+) *loggingRoundTripper {
+	if logger == nil {
+		logger = defaultLogger
+	}
+
+	return &loggingRoundTripper{
+		defaultRoundTripper: transport,
+		logger:              logger,
+		verbose:             verbose,
+		redactor:            redactor,
+	}
+}
+
+// stdoutWriter writes to the current os.Stdout, which may be replaced after defaultLogger is built.
+type stdoutWriter struct{}
+
+func (stdoutWriter) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+// defaultLogger is shared by every loggingRoundTripper without logger, it writes everything to the standard
+// output.
+var defaultLogger = logging.NewStdLogger(log.New(stdoutWriter{}, "", log.LstdFlags), logging.LevelDebug)
+
+func (l *loggingRoundTripper) getLogger() logging.Logger {
+	if l.logger == nil {
+		return defaultLogger
+	}
+
+	return l.logger
+}
+
+// defaultRedactor is shared by every loggingRoundTripper without redactor, a Redactor is not modified once
+// built.
+var defaultRedactor = logging.DefaultRedactor()
+
+func (l *loggingRoundTripper) getRedactor() *logging.Redactor {
+	if l.redactor == nil {
+		return defaultRedactor
+	}
+
+	return l.redactor
+}
+
+// requestFields returns the fields found in the request context, followed by method, URL and extra.
+func requestFields(req *http.Request, redactor *logging.Redactor, extra ...logging.Field) []logging.Field {
+	contextFields := logging.FieldsFrom(req.Context())
+
+	fields := make([]logging.Field, 0, len(contextFields)+len(extra)+2)
+	fields = append(fields, contextFields...)
+	fields = append(fields,
+		logging.F(logging.FieldMethod, req.Method),
+		logging.F(logging.FieldURL, redactor.URL(req.URL)),
+	)
+
+	return append(fields, extra...)
+}
+
+// details returns redacted headers and bodies of request and response, the response body is read and
+// replaced, so it can still be consumed by the caller.
+func details(req *http.Request, res *http.Response, redactor *logging.Redactor) []logging.Field {
+	fields := []logging.Field{logging.F("request_headers", redactor.Header(req.Header))}
+
+	if req.GetBody != nil {
+		if copyBody, err := req.GetBody(); err == nil {
+			bodyBytes, _ := ioutil.ReadAll(copyBody)
+			fields = append(fields, logging.F("request_body", redactor.Body(bodyBytes)))
+		}
+	}
+
+	fields = append(fields, logging.F("response_headers", redactor.Header(res.Header)))
+
+	bodyBytes, _ := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+
+	return append(fields, logging.F("response_body", redactor.Body(bodyBytes)))
+}
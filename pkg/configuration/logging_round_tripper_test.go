@@ -1,7 +1,9 @@
 package configuration
 
 import (
+	"accountapi-lib-form3/pkg/logging"
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -65,3 +67,165 @@ func TestLoggingRoundTripper_ShouldPrintSomething(t *testing.T) {
 		t.Errorf("wanted: value greater than 0 \n got: %d", got)
 	}
 }
+
+// loggerFake keeps every entry, levels below minLevel are not enabled.
+type loggerFake struct {
+	minLevel logging.Level
+	entries  []loggedEntryFake
+}
+
+type loggedEntryFake struct {
+	level  logging.Level
+	msg    string
+	fields map[string]interface{}
+}
+
+func (l *loggerFake) Enabled(level logging.Level) bool {
+	return level >= l.minLevel
+}
+
+func (l *loggerFake) Log(level logging.Level, msg string, fields ...logging.Field) {
+	entry := loggedEntryFake{level: level, msg: msg, fields: map[string]interface{}{}}
+	for _, field := range fields {
+		entry.fields[field.Key] = field.Value
+	}
+	l.entries = append(l.entries, entry)
+}
+
+func TestLoggingRoundTripper_ShouldLogRequestFields(t *testing.T) {
+	logger := &loggerFake{minLevel: logging.LevelInfo}
+	subject := loggingRoundTripper{defaultRoundTripper: &sequenceTransportFake{statusCodes: []int{404}}, logger: logger, verbose: true}
+
+	ctx := logging.WithFields(context.Background(), logging.F(logging.FieldOperation, "Fetch"), logging.F(logging.FieldAccountID, "id"))
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://test/v1", nil)
+	_, _ = subject.RoundTrip(req)
+
+	if len(logger.entries) != 1 {
+		t.Fatalf("entries wanted: 1, debug is not enabled\n entries got: %d", len(logger.entries))
+	}
+
+	got := logger.entries[0]
+	if got.level != logging.LevelInfo || got.msg != "request completed" {
+		t.Errorf("wanted: info request completed\n got: %v %s", got.level, got.msg)
+	}
+	for key, want := range map[string]interface{}{"operation": "Fetch", "account_id": "id", "method": "GET", "url": "http://test/v1", "status": 404} {
+		if got.fields[key] != want {
+			t.Errorf("%s wanted: %v\n %s got: %v", key, want, key, got.fields[key])
+		}
+	}
+	if _, ok := got.fields[logging.FieldLatency]; !ok {
+		t.Errorf("wanted: %s field", logging.FieldLatency)
+	}
+}
+
+func TestLoggingRoundTripper_ShouldLogDetailsOnlyWhenVerboseAndDebugEnabled(t *testing.T) {
+	dataTable := []struct {
+		testName string
+		verbose  bool
+		minLevel logging.Level
+		want     int
+	}{
+		{"verboseDebug", true, logging.LevelDebug, 2},
+		{"verboseInfo", true, logging.LevelInfo, 1},
+		{"nonVerboseDebug", false, logging.LevelDebug, 1},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			logger := &loggerFake{minLevel: v.minLevel}
+			subject := loggingRoundTripper{defaultRoundTripper: &transportFake{}, logger: logger, verbose: v.verbose}
+
+			req, _ := http.NewRequest(http.MethodPost, "http://test/v1", strings.NewReader(`{"test":"dummy request"}`))
+			res, _ := subject.RoundTrip(req)
+
+			if len(logger.entries) != v.want {
+				t.Fatalf("entries wanted: %d\n entries got: %d", v.want, len(logger.entries))
+			}
+
+			if v.want == 2 {
+				details := logger.entries[1].fields
+				if details["request_body"] != `{"test":"dummy request"}` || details["response_body"] != `{"test":"dummy response"}` {
+					t.Errorf("wanted: request and response bodies\n got: %v", details)
+				}
+			}
+
+			body, _ := ioutil.ReadAll(res.Body)
+			if string(body) != `{"test":"dummy response"}` {
+				t.Errorf("response body wanted: still readable\n got: %s", body)
+			}
+		})
+	}
+}
+
+func TestLoggingRoundTripper_ShouldLogTransportErrorsAndAttempts(t *testing.T) {
+	logger := &loggerFake{minLevel: logging.LevelInfo}
+	subject := retryRoundTripper{
+		defaultRoundTripper: &loggingRoundTripper{defaultRoundTripper: &sequenceTransportFake{statusCodes: []int{0, 200}}, logger: logger},
+		policy:              getRetryPolicyStub(),
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://test/v1", nil)
+	_, _ = subject.RoundTrip(req)
+
+	if len(logger.entries) != 2 {
+		t.Fatalf("entries wanted: 2\n entries got: %d", len(logger.entries))
+	}
+	if got := logger.entries[0]; got.level != logging.LevelError || got.fields["attempt"] != 1 || got.fields["error"] == nil {
+		t.Errorf("wanted: error entry of attempt 1\n got: %v", got)
+	}
+	if got := logger.entries[1]; got.level != logging.LevelInfo || got.fields["attempt"] != 2 {
+		t.Errorf("wanted: info entry of attempt 2\n got: %v", got)
+	}
+}
+
+func TestLoggingRoundTripper_ShouldRedactSensitiveData(t *testing.T) {
+	logger := &loggerFake{minLevel: logging.LevelDebug}
+	subject := loggingRoundTripper{defaultRoundTripper: &transportFake{}, logger: logger, verbose: true}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://test/v1?filter[iban]=GB82WEST12345698765432", strings.NewReader(`{"data":{"attributes":{"iban":"GB82WEST12345698765432"}}}`))
+	req.Header.Set("Authorization", "Signature keyId=\"key\"")
+	_, _ = subject.RoundTrip(req)
+
+	if len(logger.entries) != 2 {
+		t.Fatalf("entries wanted: 2\n entries got: %d", len(logger.entries))
+	}
+
+	details := logger.entries[1].fields
+	if got := details["request_body"]; got != `{"data":{"attributes":{"iban":"[REDACTED]"}}}` {
+		t.Errorf("request body wanted: redacted iban\n got: %v", got)
+	}
+	if got := details["request_headers"].(http.Header).Get("Authorization"); got != logging.Redacted {
+		t.Errorf("authorization wanted: %s\n got: %s", logging.Redacted, got)
+	}
+	if got := details["url"]; got != "http://test/v1?filter%5Biban%5D=%5BREDACTED%5D" {
+		t.Errorf("url wanted: redacted filter\n got: %v", got)
+	}
+	if got := req.Header.Get("Authorization"); got == logging.Redacted {
+		t.Errorf("wanted: request header untouched")
+	}
+}
+
+func TestLoggingRoundTripper_ShouldUseConfiguredRedactor(t *testing.T) {
+	logger := &loggerFake{minLevel: logging.LevelDebug}
+	subject := loggingRoundTripper{
+		defaultRoundTripper: &transportFake{},
+		logger:              logger,
+		verbose:             true,
+		redactor:            logging.NewRedactor([]string{"test"}, nil),
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://test/v1", nil)
+	_, _ = subject.RoundTrip(req)
+
+	if got := logger.entries[1].fields["response_body"]; got != `{"test":"[REDACTED]"}` {
+		t.Errorf("response body wanted: redacted test field\n got: %v", got)
+	}
+}
+
+func TestLoggingRoundTripper_ShouldStoreDefaultLoggerWhenBuilt(t *testing.T) {
+	subject := newLoggingRoundTripper(&transportFake{}, nil, nil, true)
+
+	if subject.logger != defaultLogger {
+		t.Errorf("wanted: %v\n got: %v", defaultLogger, subject.logger)
+	}
+}
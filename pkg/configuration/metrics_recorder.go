@@ -0,0 +1,43 @@
+package configuration
+
+import (
+	"accountapi-lib-form3/pkg/logging"
+	"context"
+	"time"
+)
+
+// MetricsRecorder receives measurements of every operation sent to the account API, metrics.PrometheusRecorder
+// renders them in Prometheus text exposition format. Implementations must be safe for concurrent use.
+type MetricsRecorder interface {
+	// RequestStarted is invoked before a request of operation is sent, it increases the requests in flight.
+	RequestStarted(operation string)
+	// RequestFinished is invoked once the request has finished, including its retries. statusCode is 0
+	// when no response was received.
+	RequestFinished(operation string, statusCode int, latency time.Duration)
+	// RetryAttempted is invoked before every retry of a request.
+	RetryAttempted(operation string)
+	// RequestQueued is invoked once a request has waited for the rate limit and concurrency cap.
+	RequestQueued(operation string, waited time.Duration)
+}
+
+type nopMetricsRecorder struct{}
+
+func (nopMetricsRecorder) RequestStarted(string) {}
+
+func (nopMetricsRecorder) RequestFinished(string, int, time.Duration) {}
+
+func (nopMetricsRecorder) RetryAttempted(string) {}
+
+func (nopMetricsRecorder) RequestQueued(string, time.Duration) {}
+
+// operationOf returns the operation set by AccountService in the context of the request, it is shared with
+// the logging fields, so it is available to every transport.
+func operationOf(ctx context.Context) string {
+	for _, field := range logging.FieldsFrom(ctx) {
+		if operation, ok := field.Value.(string); ok && field.Key == logging.FieldOperation {
+			return operation
+		}
+	}
+
+	return ""
+}
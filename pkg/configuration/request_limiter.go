@@ -0,0 +1,69 @@
+package configuration
+
+import (
+	"accountapi-lib-form3/pkg/ratelimit"
+	"context"
+)
+
+// RequestLimiter delays operations before they are sent to the account API. Acquire returns a function that
+// must be invoked once the operation has finished, or the context error when ctx is done while waiting.
+type RequestLimiter interface {
+	Acquire(ctx context.Context) (release func(), err error)
+}
+
+type nopRequestLimiter struct{}
+
+func (nopRequestLimiter) Acquire(context.Context) (func(), error) {
+	return func() {}, nil
+}
+
+// requestLimiter caps concurrency first and then rate, so a request is sent as soon as it gets its token.
+// Either of them may be nil.
+type requestLimiter struct {
+	bucket    *ratelimit.TokenBucket
+	semaphore *ratelimit.Semaphore
+}
+
+func (r *requestLimiter) Acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+
+	if r.semaphore != nil {
+		if err := r.semaphore.Acquire(ctx); err != nil {
+			return nil, err
+		}
+		release = r.semaphore.Release
+	}
+
+	if r.bucket != nil {
+		if err := r.bucket.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// rateLimitSettings holds the token bucket configuration, a zero rate disables it.
+type rateLimitSettings struct {
+	requestsPerSecond float64
+	burst             int
+}
+
+// buildRequestLimiter returns nil when neither rate nor concurrency are limited.
+func buildRequestLimiter(rateLimit rateLimitSettings, maxConcurrentRequests int) RequestLimiter {
+	limiter := &requestLimiter{}
+
+	if rateLimit.requestsPerSecond > 0 {
+		limiter.bucket = ratelimit.NewTokenBucket(rateLimit.requestsPerSecond, rateLimit.burst)
+	}
+	if maxConcurrentRequests > 0 {
+		limiter.semaphore = ratelimit.NewSemaphore(maxConcurrentRequests)
+	}
+
+	if limiter.bucket == nil && limiter.semaphore == nil {
+		return nil
+	}
+
+	return limiter
+}
@@ -0,0 +1,58 @@
+package configuration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestLimiter_ShouldBeDisabledByDefault(t *testing.T) {
+	subject := NewDefaultConfigBuilder().Build()
+
+	if _, ok := subject.GetRequestLimiter().(nopRequestLimiter); !ok {
+		t.Errorf("wanted: nopRequestLimiter\n got: %T", subject.GetRequestLimiter())
+	}
+}
+
+func TestRequestLimiter_ShouldCapConcurrencyUntilReleased(t *testing.T) {
+	subject := NewDefaultConfigBuilder().
+		WithRateLimit(1000, 10).
+		WithMaxConcurrentRequests(1).
+		Build().
+		GetRequestLimiter()
+
+	release, err := subject.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := subject.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wanted: %v\n got: %v", context.DeadlineExceeded, err)
+	}
+
+	release()
+	if _, err := subject.Acquire(context.Background()); err != nil {
+		t.Errorf("wanted: nil after release\n got: %v", err)
+	}
+}
+
+func TestRequestLimiter_ShouldReleaseSlotWhenRateWaitFails(t *testing.T) {
+	subject := buildRequestLimiter(rateLimitSettings{requestsPerSecond: 0.001, burst: 1}, 1)
+	release, _ := subject.Acquire(context.Background())
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := subject.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wanted: %v\n got: %v", context.DeadlineExceeded, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := subject.(*requestLimiter).semaphore.Acquire(ctx); err != nil {
+		t.Errorf("wanted: slot released\n got: %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package configuration
+
+import (
+	"accountapi-lib-form3/pkg/logging"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy defines how requests that failed because of a transient problem are retried. Only
+// idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) are retried unless RetryNonIdempotent
+// is set, in that case POST and PATCH requests are retried as well by replaying their body.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent, including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, it is doubled on every following retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, including the one asked by a Retry-After header. 0 means
+	// that the delay is not capped.
+	MaxDelay time.Duration
+	// Jitter is the fraction, between 0 and 1, of every delay that is randomised to spread retries out.
+	Jitter float64
+	// RetryableStatusCodes are the response status codes that are worth retrying.
+	RetryableStatusCodes []int
+	// RetryNonIdempotent allows to retry POST and PATCH requests.
+	RetryNonIdempotent bool
+}
+
+const (
+	defaultMaxAttempts = 3
+	defaultBaseDelay   = 100 * time.Millisecond
+	defaultMaxDelay    = 2 * time.Second
+	defaultJitter      = 0.2
+	retryAfterHeader   = "Retry-After"
+)
+
+// DefaultRetryPolicy returns a policy with 3 attempts and exponential backoff from 100ms to 2s, it
+// retries responses with 429, 500, 502, 503 and 504 status codes.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultMaxAttempts,
+		BaseDelay:   defaultBaseDelay,
+		MaxDelay:    defaultMaxDelay,
+		Jitter:      defaultJitter,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+type retryRoundTripper struct {
+	defaultRoundTripper http.RoundTripper
+	policy              RetryPolicy
+	// metrics counts retries, it may be nil
+	metrics MetricsRecorder
+}
+
+// RoundTrip sends the request until it succeeds, the response status code is not retryable or the
+// attempts defined by the policy are exhausted. Waiting between attempts stops as soon as the
+// request context is done.
+func (r *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !r.isRetryable(req) {
+		return r.defaultRoundTripper.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && r.metrics != nil {
+			r.metrics.RetryAttempted(operationOf(req.Context()))
+		}
+
+		attemptReq, err := replayableRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := r.defaultRoundTripper.RoundTrip(attemptReq)
+		if attempt >= r.policy.MaxAttempts || req.Context().Err() != nil {
+			return res, err
+		}
+
+		delay := r.backoff(attempt)
+		if err == nil {
+			if !r.isRetryableStatus(res.StatusCode) {
+				return res, nil
+			}
+
+			if retryAfter, ok := parseRetryAfter(res.Header.Get(retryAfterHeader)); ok {
+				if r.policy.MaxDelay > 0 && retryAfter > r.policy.MaxDelay {
+					return res, nil
+				}
+				if retryAfter > delay {
+					delay = retryAfter
+				}
+			}
+
+			_, _ = io.Copy(ioutil.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryable evaluates whether the request method is allowed by the policy and whether its body
+// can be sent again.
+func (r *retryRoundTripper) isRetryable(req *http.Request) bool {
+	if r.policy.MaxAttempts <= 1 {
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return r.policy.RetryNonIdempotent
+	}
+}
+
+func (r *retryRoundTripper) isRetryableStatus(statusCode int) bool {
+	for _, code := range r.policy.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff calculates the exponential delay after a given attempt, a fraction of it defined by
+// Jitter is randomised.
+func (r *retryRoundTripper) backoff(attempt int) time.Duration {
+	delay := float64(r.policy.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if maxDelay := float64(r.policy.MaxDelay); maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if r.policy.Jitter > 0 {
+		delay -= delay * r.policy.Jitter * rand.Float64() // #nosec G404 -- jitter does not need a secure source
+	}
+
+	return time.Duration(delay)
+}
+
+// replayableRequest returns the request to send in a given attempt, its context carries the attempt number,
+// so it is logged. From the second attempt on the request is cloned and its body is obtained again through GetBody.
+func replayableRequest(req *http.Request, attempt int) (*http.Request, error) {
+	ctx := logging.WithFields(req.Context(), logging.F(logging.FieldAttempt, attempt))
+
+	if attempt == 1 || req.GetBody == nil {
+		return req.WithContext(ctx), nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	attemptReq := req.Clone(ctx)
+	attemptReq.Body = body
+
+	return attemptReq, nil
+}
+
+// parseRetryAfter supports both formats of the Retry-After header, delay in seconds and HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,218 @@
+package configuration
+
+import (
+	"accountapi-lib-form3/pkg/logging"
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sequenceTransportFake answers with statusCodes in order, a status code 0 emulates a transport error.
+// Bodies of every received request are kept.
+type sequenceTransportFake struct {
+	statusCodes []int
+	headers     http.Header
+	bodies      []string
+}
+
+func (s *sequenceTransportFake) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		bodyBytes, _ := ioutil.ReadAll(req.Body)
+		body = string(bodyBytes)
+	}
+	s.bodies = append(s.bodies, body)
+
+	statusCode := s.statusCodes[len(s.bodies)-1]
+	if statusCode == 0 {
+		return nil, errors.New("connection reset")
+	}
+
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     s.headers,
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+	}, nil
+}
+
+func getRetryPolicyStub() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetryRoundTripper_ShouldRetryTransientFailures(t *testing.T) {
+	fake := &sequenceTransportFake{statusCodes: []int{503, 0, 200}}
+	subject := retryRoundTripper{defaultRoundTripper: fake, policy: getRetryPolicyStub()}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://test/v1", nil)
+	res, err := subject.RoundTrip(req)
+
+	if err != nil || res.StatusCode != 200 {
+		t.Errorf("wanted: 200\n got: %v %v", res, err)
+	}
+	if len(fake.bodies) != 3 {
+		t.Errorf("attempts wanted: 3\n attempts got: %d", len(fake.bodies))
+	}
+}
+
+func TestRetryRoundTripper_ShouldStopAfterMaxAttempts(t *testing.T) {
+	fake := &sequenceTransportFake{statusCodes: []int{503, 503, 503, 200}}
+	subject := retryRoundTripper{defaultRoundTripper: fake, policy: getRetryPolicyStub()}
+
+	req, _ := http.NewRequest(http.MethodDelete, "http://test/v1", nil)
+	res, _ := subject.RoundTrip(req)
+
+	if res.StatusCode != 503 {
+		t.Errorf("wanted: 503\n got: %d", res.StatusCode)
+	}
+	if len(fake.bodies) != 3 {
+		t.Errorf("attempts wanted: 3\n attempts got: %d", len(fake.bodies))
+	}
+}
+
+func TestRetryRoundTripper_ShouldNotRetryNonRetryableStatus(t *testing.T) {
+	fake := &sequenceTransportFake{statusCodes: []int{409, 200}}
+	subject := retryRoundTripper{defaultRoundTripper: fake, policy: getRetryPolicyStub()}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://test/v1", nil)
+	res, _ := subject.RoundTrip(req)
+
+	if res.StatusCode != 409 || len(fake.bodies) != 1 {
+		t.Errorf("wanted: 409 after 1 attempt\n got: %d after %d attempts", res.StatusCode, len(fake.bodies))
+	}
+}
+
+func TestRetryRoundTripper_ShouldNotRetryPostByDefault(t *testing.T) {
+	fake := &sequenceTransportFake{statusCodes: []int{503, 201}}
+	subject := retryRoundTripper{defaultRoundTripper: fake, policy: getRetryPolicyStub()}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://test/v1", strings.NewReader(`{"test":"dummy"}`))
+	res, _ := subject.RoundTrip(req)
+
+	if res.StatusCode != 503 || len(fake.bodies) != 1 {
+		t.Errorf("wanted: 503 after 1 attempt\n got: %d after %d attempts", res.StatusCode, len(fake.bodies))
+	}
+}
+
+func TestRetryRoundTripper_ShouldReplayBodyWhenPostIsAllowed(t *testing.T) {
+	want := `{"test":"dummy"}`
+	fake := &sequenceTransportFake{statusCodes: []int{503, 201}}
+	policy := getRetryPolicyStub()
+	policy.RetryNonIdempotent = true
+	subject := retryRoundTripper{defaultRoundTripper: fake, policy: policy}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://test/v1", strings.NewReader(want))
+	res, _ := subject.RoundTrip(req)
+
+	if res.StatusCode != 201 {
+		t.Errorf("wanted: 201\n got: %d", res.StatusCode)
+	}
+	for i, got := range fake.bodies {
+		if got != want {
+			t.Errorf("attempt %d body wanted: %s\n body got: %s", i+1, want, got)
+		}
+	}
+}
+
+func TestRetryRoundTripper_ShouldNotWaitLongerThanMaxDelay(t *testing.T) {
+	dataTable := []struct {
+		testName     string
+		maxDelay     time.Duration
+		retryAfter   string
+		wantStatus   int
+		wantAttempts int
+	}{
+		{"longerThanMaxDelay", 5 * time.Millisecond, "120", 429, 1},
+		{"withoutMaxDelay", 0, "1", 200, 2},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			fake := &sequenceTransportFake{
+				statusCodes: []int{429, 200},
+				headers:     http.Header{retryAfterHeader: {v.retryAfter}},
+			}
+			policy := getRetryPolicyStub()
+			policy.MaxDelay = v.maxDelay
+			subject := retryRoundTripper{defaultRoundTripper: fake, policy: policy}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://test/v1", nil)
+			res, _ := subject.RoundTrip(req)
+
+			if res.StatusCode != v.wantStatus || len(fake.bodies) != v.wantAttempts {
+				t.Errorf("wanted: %d after %d attempts\n got: %d after %d attempts", v.wantStatus, v.wantAttempts, res.StatusCode, len(fake.bodies))
+			}
+		})
+	}
+}
+
+func TestRetryRoundTripper_ShouldStopWaitingWhenContextIsDone(t *testing.T) {
+	fake := &sequenceTransportFake{statusCodes: []int{503, 200}}
+	policy := getRetryPolicyStub()
+	policy.BaseDelay = time.Minute
+	policy.MaxDelay = time.Minute
+	subject := retryRoundTripper{defaultRoundTripper: fake, policy: policy}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://test/v1", nil)
+	_, err := subject.RoundTrip(req)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wanted: %v\n got: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestRetryRoundTripper_ShouldParseRetryAfter(t *testing.T) {
+	dataTable := []struct {
+		testName string
+		value    string
+		want     time.Duration
+		wantOk   bool
+	}{
+		{"seconds", "3", 3 * time.Second, true},
+		{"pastDate", "Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+		{"empty", "", 0, false},
+		{"invalid", "soon", 0, false},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			got, gotOk := parseRetryAfter(v.value)
+			if got != v.want || gotOk != v.wantOk {
+				t.Errorf("wanted: %v %t\n got: %v %t", v.want, v.wantOk, got, gotOk)
+			}
+		})
+	}
+}
+
+// metricsRecorderFake counts retries by operation
+type metricsRecorderFake struct {
+	nopMetricsRecorder
+	retries map[string]int
+}
+
+func (m *metricsRecorderFake) RetryAttempted(operation string) {
+	m.retries[operation]++
+}
+
+func TestRetryRoundTripper_ShouldRecordRetriesByOperation(t *testing.T) {
+	recorder := &metricsRecorderFake{retries: map[string]int{}}
+	fake := &sequenceTransportFake{statusCodes: []int{503, 503, 200}}
+	subject := retryRoundTripper{defaultRoundTripper: fake, policy: getRetryPolicyStub(), metrics: recorder}
+
+	ctx := logging.WithFields(context.Background(), logging.F(logging.FieldOperation, "Fetch"))
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://test/v1", nil)
+	_, _ = subject.RoundTrip(req)
+
+	if recorder.retries["Fetch"] != 2 {
+		t.Errorf("retries wanted: 2\n retries got: %v", recorder.retries)
+	}
+}
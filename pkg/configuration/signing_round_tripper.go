@@ -0,0 +1,28 @@
+package configuration
+
+import (
+	"net/http"
+)
+
+// RequestSigner adds authentication headers to a request before it is sent to the account API,
+// signing.Signer implements HTTP message signatures.
+type RequestSigner interface {
+	SignRequest(req *http.Request) error
+}
+
+type signingRoundTripper struct {
+	defaultRoundTripper http.RoundTripper
+	signer              RequestSigner
+}
+
+// RoundTrip signs a clone of the request, as a http.RoundTripper must not modify the request it receives.
+// As it is invoked on every attempt of a retry policy, every attempt carries its own signature.
+func (s *signingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	signedReq := req.Clone(req.Context())
+
+	if err := s.signer.SignRequest(signedReq); err != nil {
+		return nil, err
+	}
+
+	return s.defaultRoundTripper.RoundTrip(signedReq)
+}
@@ -0,0 +1,73 @@
+package configuration
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type signerFake struct {
+	err error
+}
+
+func (s *signerFake) SignRequest(req *http.Request) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	req.Header.Set("Signature", "fake signature")
+	return nil
+}
+
+// capturingTransportFake keeps the last request it received.
+type capturingTransportFake struct {
+	req *http.Request
+}
+
+func (c *capturingTransportFake) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.req = req
+	return &http.Response{StatusCode: 200}, nil
+}
+
+func TestSigningRoundTripper_ShouldSignCloneOfRequest(t *testing.T) {
+	fake := &capturingTransportFake{}
+	subject := signingRoundTripper{defaultRoundTripper: fake, signer: &signerFake{}}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://test/v1", nil)
+	_, _ = subject.RoundTrip(req)
+
+	if got := fake.req.Header.Get("Signature"); got != "fake signature" {
+		t.Errorf("wanted: fake signature\n got: %s", got)
+	}
+	if got := req.Header.Get("Signature"); got != "" {
+		t.Errorf("wanted: original request not modified\n got: %s", got)
+	}
+}
+
+func TestSigningRoundTripper_ShouldReturnSignerError(t *testing.T) {
+	want := errors.New("fake error")
+	fake := &capturingTransportFake{}
+	subject := signingRoundTripper{defaultRoundTripper: fake, signer: &signerFake{err: want}}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://test/v1", nil)
+	_, got := subject.RoundTrip(req)
+
+	if got != want {
+		t.Errorf("wanted: %v\n got: %v", want, got)
+	}
+	if fake.req != nil {
+		t.Errorf("wanted: request not sent")
+	}
+}
+
+func TestConfigBuilder_ShouldSetRequestSigner(t *testing.T) {
+	want := &signerFake{}
+	subject := NewDefaultConfigBuilder().
+		WithRequestSigner(want).
+		Build()
+
+	got, ok := subject.GetHttpClient().Transport.(*signingRoundTripper)
+	if !ok || got.signer != want {
+		t.Errorf("wanted: signingRoundTripper with %v\n got: %#v", want, subject.GetHttpClient().Transport)
+	}
+}
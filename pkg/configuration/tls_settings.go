@@ -0,0 +1,60 @@
+package configuration
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+const defaultMinTLSVersion = tls.VersionTLS12
+
+// LoadRootCAs creates a certificate pool from one or more PEM files, every file has to contain at
+// least one certificate.
+func LoadRootCAs(pemFiles ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	for _, pemFile := range pemFiles {
+		pemBytes, err := ioutil.ReadFile(pemFile) // #nosec G304 -- path is provided by the library user
+		if err != nil {
+			return nil, fmt.Errorf("failed reading root CAs: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(pemBytes) {
+			return nil, fmt.Errorf("failed reading root CAs: no certificate found in %s", pemFile)
+		}
+	}
+
+	return pool, nil
+}
+
+// LoadClientCertificate loads a certificate and its private key from PEM files in order to use
+// mutual TLS.
+func LoadClientCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed reading client certificate: %w", err)
+	}
+
+	return certificate, nil
+}
+
+// build returns the tls.Config for the default http.Client, or nil when nothing was configured, so
+// the http.DefaultTransport can be used as it is.
+func (t tlsSettings) build() *tls.Config {
+	if t.rootCAs == nil && len(t.clientCertificates) == 0 && t.minVersion == 0 && t.serverName == "" {
+		return nil
+	}
+
+	minVersion := t.minVersion
+	if minVersion == 0 {
+		minVersion = defaultMinTLSVersion
+	}
+
+	return &tls.Config{
+		RootCAs:      t.rootCAs,
+		Certificates: t.clientCertificates,
+		MinVersion:   minVersion,
+		ServerName:   t.serverName,
+	}
+}
@@ -0,0 +1,105 @@
+package configuration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificateStub generates a self-signed certificate and writes it and its key as PEM files.
+func writeCertificateStub(t *testing.T) (string, string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "accountapi"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	certDER, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	_ = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0600)
+	_ = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+
+	return certFile, keyFile
+}
+
+func TestTLSSettings_ShouldLoadRootCAs(t *testing.T) {
+	certFile, _ := writeCertificateStub(t)
+
+	got, err := LoadRootCAs(certFile)
+
+	if err != nil || got == nil {
+		t.Errorf("wanted: certificate pool\n got: %v %v", got, err)
+	}
+}
+
+func TestTLSSettings_ShouldFailLoadingInvalidRootCAs(t *testing.T) {
+	_, keyFile := writeCertificateStub(t)
+
+	dataTable := []struct {
+		testName string
+		file     string
+	}{
+		{"missingFile", filepath.Join(t.TempDir(), "missing.pem")},
+		{"noCertificate", keyFile},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			_, err := LoadRootCAs(v.file)
+			if err == nil {
+				t.Errorf("wanted: error\n got: nil")
+			}
+		})
+	}
+}
+
+func TestTLSSettings_ShouldLoadClientCertificate(t *testing.T) {
+	certFile, keyFile := writeCertificateStub(t)
+
+	got, err := LoadClientCertificate(certFile, keyFile)
+
+	if err != nil || len(got.Certificate) != 1 {
+		t.Errorf("wanted: client certificate\n got: %v %v", got, err)
+	}
+
+	_, err = LoadClientCertificate(keyFile, certFile)
+	if err == nil {
+		t.Errorf("wanted: error with swapped files\n got: nil")
+	}
+}
+
+func TestTLSSettings_ShouldBuildNilWhenNothingIsConfigured(t *testing.T) {
+	got := tlsSettings{}.build()
+
+	if got != nil {
+		t.Errorf("wanted: nil\n got: %v", got)
+	}
+}
+
+func TestTLSSettings_ShouldBuildTLSConfig(t *testing.T) {
+	subject := tlsSettings{serverName: "accountapi"}
+
+	got := subject.build()
+
+	if got.MinVersion != tls.VersionTLS12 {
+		t.Errorf("min version wanted: %d\n min version got: %d", tls.VersionTLS12, got.MinVersion)
+	}
+	if got.ServerName != "accountapi" {
+		t.Errorf("server name wanted: accountapi\n server name got: %s", got.ServerName)
+	}
+}
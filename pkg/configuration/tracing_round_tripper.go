@@ -0,0 +1,19 @@
+package configuration
+
+import (
+	"accountapi-lib-form3/pkg/tracing"
+	"net/http"
+)
+
+type tracingRoundTripper struct {
+	defaultRoundTripper http.RoundTripper
+}
+
+// RoundTrip adds traceparent, tracestate and X-Request-ID headers to a clone of the request, taken from its
+// context. As it is added after the retry policy, every attempt carries the same request ID.
+func (t *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	tracedReq := req.Clone(req.Context())
+	tracing.Inject(req.Context(), tracedReq.Header)
+
+	return t.defaultRoundTripper.RoundTrip(tracedReq)
+}
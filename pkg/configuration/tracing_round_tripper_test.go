@@ -0,0 +1,65 @@
+package configuration
+
+import (
+	"accountapi-lib-form3/pkg/tracing"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestTracingRoundTripper_ShouldInjectHeadersFromContext(t *testing.T) {
+	fake := &capturingTransportFake{}
+	subject := tracingRoundTripper{defaultRoundTripper: fake}
+
+	spanContext, _ := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	spanContext.TraceState = "congo=t61rcWkgMzE"
+	ctx := tracing.ContextWithRemoteSpanContext(context.Background(), spanContext)
+	ctx = tracing.WithRequestID(ctx, "request-1")
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://test/v1", nil)
+	_, _ = subject.RoundTrip(req)
+
+	want := map[string]string{
+		"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"tracestate":   "congo=t61rcWkgMzE",
+		"X-Request-ID": "request-1",
+	}
+	for header, value := range want {
+		if got := fake.req.Header.Get(header); got != value {
+			t.Errorf("%s wanted: %s\n %s got: %s", header, value, header, got)
+		}
+	}
+	if got := req.Header.Get("X-Request-ID"); got != "" {
+		t.Errorf("wanted: original request not modified\n got: %s", got)
+	}
+}
+
+func TestTracingRoundTripper_ShouldGenerateRequestIDWithoutTraceContext(t *testing.T) {
+	fake := &capturingTransportFake{}
+	subject := tracingRoundTripper{defaultRoundTripper: fake}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://test/v1", nil)
+	_, _ = subject.RoundTrip(req)
+
+	if got := fake.req.Header.Get("X-Request-ID"); len(got) != 36 {
+		t.Errorf("wanted: generated request ID\n got: %s", got)
+	}
+	if got := fake.req.Header.Get("traceparent"); got != "" {
+		t.Errorf("wanted: no traceparent\n got: %s", got)
+	}
+}
+
+func TestConfigBuilder_ShouldSetTracing(t *testing.T) {
+	subject := NewDefaultConfigBuilder().
+		WithRetryPolicy(DefaultRetryPolicy()).
+		WithTracer(tracing.NopTracer()).
+		Build()
+
+	transport, ok := subject.GetHttpClient().Transport.(*tracingRoundTripper)
+	if !ok {
+		t.Fatalf("wanted: *configuration.tracingRoundTripper\n got: %T", subject.GetHttpClient().Transport)
+	}
+	if _, ok := transport.defaultRoundTripper.(*retryRoundTripper); !ok {
+		t.Errorf("wanted: tracing added after retries\n got: %T", transport.defaultRoundTripper)
+	}
+}
@@ -1,18 +1,74 @@
 package error_handling
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
+// minStatusCode is the lowest code considered a HTTP status code instead of an internal code
+const minStatusCode = http.StatusContinue
+
+// AccountError is returned by every operation of the library. Code keeps the legacy meaning, an internal
+// code from 1 to 13, defined by package api_client and listed in the README, or the status code returned by the
+// account API, whereas Kind classifies the error and StatusCode is only set when the account API answered. The
+// cause is preserved and reachable through Unwrap.
 type AccountError struct {
-	operation string
-	code      int
-	message   string
+	operation  string
+	code       int
+	message    string
+	err        error
+	kind       Kind
+	statusCode int
 }
 
+// NewAccountError creates an error from a code, when code is a HTTP status code the error is classified
+// as NewStatusError does, otherwise its kind is KindUnknown.
 func NewAccountError(operation string, code int, message string) error {
+	return NewAccountErrorWithCause(operation, code, message, nil)
+}
+
+// NewAccountErrorWithCause works as NewAccountError, but it keeps cause, so it can be reached by
+// errors.Is and errors.As through Unwrap.
+func NewAccountErrorWithCause(operation string, code int, message string, cause error) error {
+	if code >= minStatusCode {
+		return newStatusError(operation, code, message, cause)
+	}
+
 	return &AccountError{
 		operation: operation,
 		code:      code,
 		message:   message,
+		err:       cause,
+		kind:      KindUnknown,
+	}
+}
+
+// NewInternalError creates an error that happened before or after invoking the account API, code is
+// one of the internal codes and cause is the original error, if any.
+func NewInternalError(operation string, kind Kind, code int, message string, cause error) error {
+	return &AccountError{
+		operation: operation,
+		code:      code,
+		message:   message,
+		err:       cause,
+		kind:      kind,
+	}
+}
+
+// NewStatusError creates an error from a response of the account API, its kind is derived from statusCode.
+func NewStatusError(operation string, statusCode int, message string) error {
+	return newStatusError(operation, statusCode, message, nil)
+}
+
+func newStatusError(operation string, statusCode int, message string, cause error) error {
+	return &AccountError{
+		operation:  operation,
+		code:       statusCode,
+		message:    message,
+		err:        cause,
+		kind:       kindFromStatus(statusCode),
+		statusCode: statusCode,
 	}
 }
 
@@ -20,6 +76,16 @@ func (ce *AccountError) Error() string {
 	return fmt.Sprintf("%s: %d - %s", ce.operation, ce.code, ce.message)
 }
 
+func (ce *AccountError) Unwrap() error {
+	return ce.err
+}
+
+// Is reports whether target is the sentinel error of this error kind, e.g. errors.Is(err, ErrNotFound).
+func (ce *AccountError) Is(target error) bool {
+	sentinel, ok := sentinels[ce.kind]
+	return ok && sentinel == target
+}
+
 func (ce *AccountError) GetOperation() string {
 	return ce.operation
 }
@@ -31,3 +97,24 @@ func (ce *AccountError) GetCode() int {
 func (ce *AccountError) GetMessage() string {
 	return ce.message
 }
+
+func (ce *AccountError) GetKind() Kind {
+	return ce.kind
+}
+
+// GetStatusCode returns the status code answered by the account API, or 0 when the error happened before
+// getting a response.
+func (ce *AccountError) GetStatusCode() int {
+	return ce.statusCode
+}
+
+// KindOf returns the kind of the first AccountError found in the chain of err, or KindUnknown when
+// there is none.
+func KindOf(err error) Kind {
+	var accountErr *AccountError
+	if errors.As(err, &accountErr) {
+		return accountErr.kind
+	}
+
+	return KindUnknown
+}
@@ -1,6 +1,8 @@
 package error_handling
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -70,3 +72,98 @@ func TestAccountError_ShouldReturnOperation(t *testing.T) {
 		t.Errorf("wanted: %v\n got: %v", want, got)
 	}
 }
+
+func TestAccountError_ShouldUnwrapCause(t *testing.T) {
+
+	subject := NewAccountErrorWithCause("test", 409, "test error", ErrVersionConflict)
+
+	if !errors.Is(subject, ErrVersionConflict) {
+		t.Errorf("wanted: %v\n got: %v", ErrVersionConflict, errors.Unwrap(subject))
+	}
+}
+
+func TestAccountError_ShouldNotUnwrapWithoutCause(t *testing.T) {
+
+	subject := NewAccountError("test", 409, "test error")
+
+	if errors.Is(subject, ErrVersionConflict) {
+		t.Errorf("wanted: no cause\n got: %v", errors.Unwrap(subject))
+	}
+}
+
+func TestAccountError_ShouldClassifyStatusCodes(t *testing.T) {
+
+	dataTable := []struct {
+		statusCode int
+		want       error
+		wantKind   Kind
+	}{
+		{400, ErrValidation, KindValidation},
+		{401, ErrUnauthorized, KindUnauthorized},
+		{403, ErrUnauthorized, KindUnauthorized},
+		{404, ErrNotFound, KindNotFound},
+		{409, ErrConflict, KindConflict},
+		{429, ErrRateLimited, KindRateLimited},
+		{500, ErrServer, KindServer},
+		{503, ErrServer, KindServer},
+		{405, ErrUnexpectedStatus, KindUnexpectedStatus},
+	}
+
+	for _, v := range dataTable {
+		subject := NewStatusError("test", v.statusCode, "test error")
+		accountErr := subject.(*AccountError)
+
+		if !errors.Is(subject, v.want) {
+			t.Errorf("%d wanted: %v\n got: %v", v.statusCode, v.want, subject)
+		}
+		if accountErr.GetKind() != v.wantKind {
+			t.Errorf("%d kind wanted: %v\n kind got: %v", v.statusCode, v.wantKind, accountErr.GetKind())
+		}
+		if accountErr.GetStatusCode() != v.statusCode || accountErr.GetCode() != v.statusCode {
+			t.Errorf("%d wanted as status code and code\n got: %d and %d", v.statusCode, accountErr.GetStatusCode(), accountErr.GetCode())
+		}
+	}
+}
+
+func TestAccountError_ShouldKeepInternalErrorCause(t *testing.T) {
+
+	cause := errors.New("connection reset")
+	subject := NewInternalError("test", KindTransport, 3, "failed invoking backend: connection reset", cause)
+
+	if !errors.Is(subject, ErrTransport) {
+		t.Errorf("wanted: %v\n got: %v", ErrTransport, subject)
+	}
+	if !errors.Is(subject, cause) {
+		t.Errorf("wanted: %v\n got: %v", cause, errors.Unwrap(subject))
+	}
+	if errors.Is(subject, ErrNotFound) {
+		t.Errorf("wanted: not %v\n got: %v", ErrNotFound, subject)
+	}
+	if got := subject.(*AccountError).GetStatusCode(); got != 0 {
+		t.Errorf("status code wanted: 0\n status code got: %d", got)
+	}
+}
+
+func TestAccountError_ShouldMatchConflictAndVersionConflict(t *testing.T) {
+
+	subject := NewAccountErrorWithCause("test", 409, "invalid version", ErrVersionConflict)
+
+	if !errors.Is(subject, ErrConflict) || !errors.Is(subject, ErrVersionConflict) {
+		t.Errorf("wanted: %v and %v\n got: %v", ErrConflict, ErrVersionConflict, subject)
+	}
+}
+
+func TestAccountError_ShouldReturnKindOfWrappedError(t *testing.T) {
+
+	subject := fmt.Errorf("wrapped: %w", NewStatusError("test", 404, ""))
+
+	if got := KindOf(subject); got != KindNotFound {
+		t.Errorf("wanted: %v\n got: %v", KindNotFound, got)
+	}
+	if got := KindOf(errors.New("other")); got != KindUnknown {
+		t.Errorf("wanted: %v\n got: %v", KindUnknown, got)
+	}
+	if got := KindNotFound.String(); got != "not_found" {
+		t.Errorf("wanted: not_found\n got: %s", got)
+	}
+}
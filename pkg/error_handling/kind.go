@@ -0,0 +1,116 @@
+package error_handling
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind classifies an AccountError regardless of whether it happened internally or in the account API.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindEncode
+	KindRequest
+	KindTransport
+	KindDecode
+	KindCanceled
+	KindDeadlineExceeded
+	KindValidation
+	KindUnauthorized
+	KindNotFound
+	KindConflict
+	KindRateLimited
+	KindServer
+	KindUnexpectedStatus
+	KindCircuitOpen
+)
+
+// Sentinel errors of every kind, they are meant to be used with errors.Is.
+var (
+	ErrEncode           = errors.New("failed encoding request")
+	ErrRequest          = errors.New("failed creating request")
+	ErrTransport        = errors.New("failed invoking backend")
+	ErrDecode           = errors.New("failed decoding response")
+	ErrCanceled         = errors.New("request canceled")
+	ErrDeadlineExceeded = errors.New("request deadline exceeded")
+	ErrValidation       = errors.New("invalid request")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrNotFound         = errors.New("resource not found")
+	ErrConflict         = errors.New("resource conflict")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrServer           = errors.New("account API server error")
+	ErrUnexpectedStatus = errors.New("unexpected status code")
+	ErrCircuitOpen      = errors.New("circuit breaker is open")
+
+	// ErrVersionConflict is the cause of an AccountError returned when an update is sent with a version
+	// that is not the current version of the account. As its kind is KindConflict, ErrConflict matches too.
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrBatchAborted is the cause of an AccountError returned for the items of a fail-fast batch that were not
+	// sent, or were interrupted, after another item failed. As its kind is KindCanceled, ErrCanceled matches too.
+	ErrBatchAborted = errors.New("batch aborted")
+)
+
+var sentinels = map[Kind]error{
+	KindEncode:           ErrEncode,
+	KindRequest:          ErrRequest,
+	KindTransport:        ErrTransport,
+	KindDecode:           ErrDecode,
+	KindCanceled:         ErrCanceled,
+	KindDeadlineExceeded: ErrDeadlineExceeded,
+	KindValidation:       ErrValidation,
+	KindUnauthorized:     ErrUnauthorized,
+	KindNotFound:         ErrNotFound,
+	KindConflict:         ErrConflict,
+	KindRateLimited:      ErrRateLimited,
+	KindServer:           ErrServer,
+	KindUnexpectedStatus: ErrUnexpectedStatus,
+	KindCircuitOpen:      ErrCircuitOpen,
+}
+
+var kindNames = map[Kind]string{
+	KindUnknown:          "unknown",
+	KindEncode:           "encode",
+	KindRequest:          "request",
+	KindTransport:        "transport",
+	KindDecode:           "decode",
+	KindCanceled:         "canceled",
+	KindDeadlineExceeded: "deadline_exceeded",
+	KindValidation:       "validation",
+	KindUnauthorized:     "unauthorized",
+	KindNotFound:         "not_found",
+	KindConflict:         "conflict",
+	KindRateLimited:      "rate_limited",
+	KindServer:           "server",
+	KindUnexpectedStatus: "unexpected_status",
+	KindCircuitOpen:      "circuit_open",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+
+	return kindNames[KindUnknown]
+}
+
+// kindFromStatus classifies a status code answered by the account API.
+func kindFromStatus(statusCode int) Kind {
+	switch {
+	case statusCode == http.StatusBadRequest:
+		return KindValidation
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return KindUnauthorized
+	case statusCode == http.StatusNotFound:
+		return KindNotFound
+	case statusCode == http.StatusConflict:
+		return KindConflict
+	case statusCode == http.StatusTooManyRequests:
+		return KindRateLimited
+	case statusCode >= http.StatusInternalServerError:
+		return KindServer
+	default:
+		return KindUnexpectedStatus
+	}
+}
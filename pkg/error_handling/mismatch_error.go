@@ -0,0 +1,39 @@
+package error_handling
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrAccountMismatch matches an AccountMismatchError through errors.Is.
+var ErrAccountMismatch = errors.New("existing account differs from the requested one")
+
+// FieldDifference is a field whose requested value differs from the value of the existing account, Field is
+// the JSON path of the field within the account data, e.g. attributes.bank_id.
+type FieldDifference struct {
+	Field     string
+	Requested interface{}
+	Existing  interface{}
+}
+
+// AccountMismatchError is the cause of the AccountError returned when an account is ensured, but an account
+// with the same ID and different data already exists. As its kind is KindConflict, ErrConflict matches too.
+type AccountMismatchError struct {
+	AccountID   string
+	Differences []FieldDifference
+}
+
+func (m *AccountMismatchError) Error() string {
+	fields := make([]string, 0, len(m.Differences))
+	for _, difference := range m.Differences {
+		fields = append(fields, fmt.Sprintf("%s (requested %v, existing %v)", difference.Field, difference.Requested, difference.Existing))
+	}
+
+	return fmt.Sprintf("account %s already exists with different %s", m.AccountID, strings.Join(fields, ", "))
+}
+
+// Is reports whether target is ErrAccountMismatch.
+func (m *AccountMismatchError) Is(target error) bool {
+	return target == ErrAccountMismatch
+}
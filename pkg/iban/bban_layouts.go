@@ -0,0 +1,180 @@
+package iban
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bbanLayout builds the BBAN of a country, including national check digits when the country has them.
+type bbanLayout func(c Components) (string, error)
+
+var (
+	digitsPattern       = regexp.MustCompile(`^[0-9]+$`)
+	alphanumericPattern = regexp.MustCompile(`^[0-9A-Z]+$`)
+	bicBankCodePattern  = regexp.MustCompile(`^[A-Z]{4}`)
+)
+
+var bbanLayouts = map[string]bbanLayout{
+	"GB": bicBankCodeLayout(6, 8),
+	"IE": bicBankCodeLayout(6, 8),
+	"NL": bicBankCodeLayout(0, 10),
+	"DE": func(c Components) (string, error) {
+		return numericBBAN(c, 8, 10)
+	},
+	"BE": func(c Components) (string, error) {
+		bban, err := numericBBAN(c, 3, 7)
+		if err != nil {
+			return "", err
+		}
+
+		base, _ := strconv.ParseInt(bban, 10, 64)
+		check := base % mod97
+		if check == 0 {
+			check = mod97
+		}
+
+		return fmt.Sprintf("%s%02d", bban, check), nil
+	},
+	"ES": func(c Components) (string, error) {
+		if err := checkComponent("bank ID", c.BankID, 8, digitsPattern); err != nil {
+			return "", err
+		}
+		account, err := padComponent("account number", c.AccountNumber, 10, digitsPattern)
+		if err != nil {
+			return "", err
+		}
+
+		return c.BankID + spanishCheckDigit("00"+c.BankID) + spanishCheckDigit(account) + account, nil
+	},
+	"FR": func(c Components) (string, error) {
+		if err := checkComponent("bank ID", c.BankID, 10, digitsPattern); err != nil {
+			return "", err
+		}
+		account, err := padComponent("account number", c.AccountNumber, 11, alphanumericPattern)
+		if err != nil {
+			return "", err
+		}
+
+		return c.BankID + account + frenchRIBKey(c.BankID[:5], c.BankID[5:], account), nil
+	},
+	// the bank ID is the 5 digit ABI followed by the 5 digit CAB, the same length is required by package rules
+	"IT": func(c Components) (string, error) {
+		if err := checkComponent("bank ID", c.BankID, 10, digitsPattern); err != nil {
+			return "", err
+		}
+		account, err := padComponent("account number", c.AccountNumber, 12, alphanumericPattern)
+		if err != nil {
+			return "", err
+		}
+
+		return italianCIN(c.BankID+account) + c.BankID + account, nil
+	},
+}
+
+// bicBankCodeLayout builds BBANs made of the first four letters of the BIC, a bank ID of bankIDLength
+// digits and an account number of accountLength digits, left padded with zeros.
+func bicBankCodeLayout(bankIDLength int, accountLength int) bbanLayout {
+	return func(c Components) (string, error) {
+		bankCode := bicBankCodePattern.FindString(c.Bic)
+		if bankCode == "" {
+			return "", fmt.Errorf("%w: BIC is required to get the bank code", ErrInvalidComponent)
+		}
+
+		if bankIDLength == 0 {
+			account, err := padComponent("account number", c.AccountNumber, accountLength, digitsPattern)
+			return bankCode + account, err
+		}
+
+		bban, err := numericBBAN(c, bankIDLength, accountLength)
+		return bankCode + bban, err
+	}
+}
+
+// numericBBAN joins a bank ID of exactly bankIDLength digits and an account number of up to accountLength
+// digits, left padded with zeros.
+func numericBBAN(c Components, bankIDLength int, accountLength int) (string, error) {
+	if err := checkComponent("bank ID", c.BankID, bankIDLength, digitsPattern); err != nil {
+		return "", err
+	}
+
+	account, err := padComponent("account number", c.AccountNumber, accountLength, digitsPattern)
+	if err != nil {
+		return "", err
+	}
+
+	return c.BankID + account, nil
+}
+
+func checkComponent(name string, value string, length int, pattern *regexp.Regexp) error {
+	if len(value) != length || !pattern.MatchString(value) {
+		return fmt.Errorf("%w: %s must have %d characters", ErrInvalidComponent, name, length)
+	}
+
+	return nil
+}
+
+func padComponent(name string, value string, length int, pattern *regexp.Regexp) (string, error) {
+	if value == "" || len(value) > length || !pattern.MatchString(value) {
+		return "", fmt.Errorf("%w: %s must have up to %d characters", ErrInvalidComponent, name, length)
+	}
+
+	return strings.Repeat("0", length-len(value)) + value, nil
+}
+
+// spanishCheckDigit calculates one of the two "dígitos de control" of a Spanish account, over a ten digit value.
+func spanishCheckDigit(value string) string {
+	weights := []int{1, 2, 4, 8, 5, 10, 9, 7, 3, 6}
+
+	sum := 0
+	for i, d := range value {
+		sum += int(d-'0') * weights[i]
+	}
+
+	digit := 11 - sum%11
+	switch digit {
+	case 11:
+		digit = 0
+	case 10:
+		digit = 1
+	}
+
+	return strconv.Itoa(digit)
+}
+
+// frenchRIBKey calculates the "clé RIB" of a French account, letters of the account number are converted
+// to digits as defined by the RIB algorithm.
+func frenchRIBKey(bank string, branch string, account string) string {
+	converted := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return '0' + rune([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 1, 2, 3, 4, 5, 6, 7, 8, 9, 2, 3, 4, 5, 6, 7, 8, 9}[r-'A'])
+		}
+		return r
+	}, account)
+
+	remainder, _ := mod97Remainder(bank + branch + converted + "00")
+
+	return fmt.Sprintf("%02d", mod97-remainder)
+}
+
+// italianCIN calculates the check character of an Italian account over ABI, CAB and account number.
+func italianCIN(value string) string {
+	oddValues := []int{1, 0, 5, 7, 9, 13, 15, 17, 19, 21, 2, 4, 18, 20, 11, 3, 6, 8, 12, 14, 16, 10, 22, 25, 24, 23}
+
+	sum := 0
+	for i, r := range value {
+		index := int(r - 'A')
+		if r >= '0' && r <= '9' {
+			index = int(r - '0')
+		}
+
+		if i%2 == 0 {
+			sum += oddValues[index]
+		} else {
+			sum += index
+		}
+	}
+
+	return string(rune('A' + sum%26))
+}
@@ -0,0 +1,164 @@
+// Package iban computes ISO 13616 check digits, generates IBANs from national account details and
+// validates and formats existing IBANs.
+package iban
+
+import (
+	"accountapi-lib-form3/pkg/models"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedCountry = errors.New("unsupported country")
+	ErrInvalidFormat      = errors.New("invalid IBAN format")
+	ErrInvalidLength      = errors.New("invalid IBAN length")
+	ErrInvalidChecksum    = errors.New("invalid IBAN check digits")
+	ErrInvalidComponent   = errors.New("invalid account details")
+)
+
+const (
+	printGroupSize = 4
+	mod97          = 97
+)
+
+var electronicPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]+$`)
+
+// ibanLengths holds the IBAN length of countries whose IBANs can be validated, countries with a BBAN
+// layout in bbanLayouts can also be generated.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AT": 20, "BE": 16, "BG": 22, "BH": 22, "CH": 21, "CY": 28, "CZ": 24, "DE": 22,
+	"DK": 18, "EE": 20, "ES": 24, "FI": 18, "FO": 18, "FR": 27, "GB": 22, "GI": 23, "GL": 18, "GR": 27,
+	"HR": 21, "HU": 28, "IE": 22, "IL": 23, "IS": 26, "IT": 27, "LI": 21, "LT": 20, "LU": 20, "LV": 21,
+	"MC": 27, "MT": 31, "NL": 18, "NO": 15, "PL": 28, "PT": 25, "RO": 24, "SA": 24, "SE": 24, "SI": 19,
+	"SK": 24, "SM": 27, "TR": 26,
+}
+
+// Components are the national account details an IBAN is generated from, BankID and AccountNumber
+// follow the format of AccountAttributes. Bic is only needed by countries whose BBAN starts with the
+// bank code of the BIC, such as GB, IE and NL.
+type Components struct {
+	Country       string
+	BankID        string
+	AccountNumber string
+	Bic           string
+}
+
+// CheckDigits computes the two ISO 13616 mod-97 check digits of a country and BBAN.
+func CheckDigits(country string, bban string) (string, error) {
+	remainder, err := mod97Remainder(bban + country + "00")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%02d", mod97+1-remainder), nil
+}
+
+// Generate builds the IBAN of the given components in electronic form, using the BBAN layout of its country.
+func Generate(c Components) (string, error) {
+	layout, ok := bbanLayouts[c.Country]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCountry, c.Country)
+	}
+
+	bban, err := layout(c)
+	if err != nil {
+		return "", err
+	}
+
+	checkDigits, err := CheckDigits(c.Country, bban)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Country + checkDigits + bban, nil
+}
+
+// FromAttributes generates the IBAN of account attributes, so Iban can be prefilled before creating the account.
+func FromAttributes(attributes *models.AccountAttributes) (string, error) {
+	if attributes.Country == nil {
+		return "", fmt.Errorf("%w: country is required", ErrInvalidComponent)
+	}
+
+	return Generate(Components{
+		Country:       *attributes.Country,
+		BankID:        attributes.BankID,
+		AccountNumber: attributes.AccountNumber,
+		Bic:           attributes.Bic,
+	})
+}
+
+// Validate checks format, country length and check digits of an IBAN, both electronic and print forms
+// are accepted.
+func Validate(iban string) error {
+	electronic := Electronic(iban)
+
+	if !electronicPattern.MatchString(electronic) {
+		return ErrInvalidFormat
+	}
+
+	length, ok := ibanLengths[electronic[:2]]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCountry, electronic[:2])
+	}
+	if len(electronic) != length {
+		return fmt.Errorf("%w: %s IBANs have %d characters", ErrInvalidLength, electronic[:2], length)
+	}
+
+	remainder, err := mod97Remainder(electronic[4:] + electronic[:4])
+	if err != nil {
+		return err
+	}
+	if remainder != 1 {
+		return ErrInvalidChecksum
+	}
+
+	return nil
+}
+
+// Electronic returns the IBAN without spaces and in upper case, as it is sent to the account API.
+func Electronic(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// Print returns the IBAN in groups of four characters separated by spaces, as it is shown to people.
+func Print(iban string) string {
+	electronic := Electronic(iban)
+	groups := make([]string, 0, len(electronic)/printGroupSize+1)
+
+	for start := 0; start < len(electronic); start += printGroupSize {
+		end := start + printGroupSize
+		if end > len(electronic) {
+			end = len(electronic)
+		}
+		groups = append(groups, electronic[start:end])
+	}
+
+	return strings.Join(groups, " ")
+}
+
+// mod97Remainder converts letters to numbers (A = 10 ... Z = 35) and calculates the remainder of the
+// division by 97 piecewise, so it does not overflow.
+func mod97Remainder(value string) (int, error) {
+	remainder := 0
+
+	for _, r := range value {
+		var digits string
+		switch {
+		case r >= '0' && r <= '9':
+			digits = string(r)
+		case r >= 'A' && r <= 'Z':
+			digits = strconv.Itoa(int(r-'A') + 10)
+		default:
+			return 0, fmt.Errorf("%w: unexpected character %q", ErrInvalidFormat, r)
+		}
+
+		for _, d := range digits {
+			remainder = (remainder*10 + int(d-'0')) % mod97
+		}
+	}
+
+	return remainder, nil
+}
@@ -0,0 +1,128 @@
+package iban
+
+import (
+	"accountapi-lib-form3/pkg/models"
+	"errors"
+	"testing"
+)
+
+func TestIban_ShouldGenerateIbanOfEveryLayout(t *testing.T) {
+	dataTable := []struct {
+		testName   string
+		components Components
+		want       string
+	}{
+		{"GB", Components{Country: "GB", BankID: "123456", AccountNumber: "98765432", Bic: "WESTGB22"}, "GB82WEST12345698765432"},
+		{"IE", Components{Country: "IE", BankID: "931152", AccountNumber: "12345678", Bic: "AIBKIE2D"}, "IE29AIBK93115212345678"},
+		{"NL", Components{Country: "NL", AccountNumber: "417164300", Bic: "ABNANL2A"}, "NL91ABNA0417164300"},
+		{"DE", Components{Country: "DE", BankID: "37040044", AccountNumber: "532013000"}, "DE89370400440532013000"},
+		{"BE", Components{Country: "BE", BankID: "539", AccountNumber: "75470"}, "BE68539007547034"},
+		{"ES", Components{Country: "ES", BankID: "21000418", AccountNumber: "0200051332"}, "ES9121000418450200051332"},
+		{"FR", Components{Country: "FR", BankID: "2004101005", AccountNumber: "0500013M026"}, "FR1420041010050500013M02606"},
+		{"IT", Components{Country: "IT", BankID: "0542811101", AccountNumber: "123456"}, "IT60X0542811101000000123456"},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			got, err := Generate(v.components)
+
+			if err != nil {
+				t.Fatalf("wanted: nil\n got: %v", err)
+			}
+			if got != v.want {
+				t.Errorf("wanted: %s\n got: %s", v.want, got)
+			}
+			if err := Validate(got); err != nil {
+				t.Errorf("generated IBAN is not valid: %v", err)
+			}
+		})
+	}
+}
+
+func TestIban_ShouldRejectInvalidComponents(t *testing.T) {
+	dataTable := []struct {
+		testName   string
+		components Components
+		want       error
+	}{
+		{"unsupportedCountry", Components{Country: "US", BankID: "021000021", AccountNumber: "123456"}, ErrUnsupportedCountry},
+		{"missingBic", Components{Country: "GB", BankID: "123456", AccountNumber: "98765432"}, ErrInvalidComponent},
+		{"shortBankID", Components{Country: "DE", BankID: "3704", AccountNumber: "532013000"}, ErrInvalidComponent},
+		{"longAccountNumber", Components{Country: "BE", BankID: "539", AccountNumber: "123456789"}, ErrInvalidComponent},
+		{"lettersInAccountNumber", Components{Country: "ES", BankID: "21000418", AccountNumber: "02000A1332"}, ErrInvalidComponent},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			_, err := Generate(v.components)
+
+			if !errors.Is(err, v.want) {
+				t.Errorf("wanted: %v\n got: %v", v.want, err)
+			}
+		})
+	}
+}
+
+func TestIban_ShouldGenerateFromAttributes(t *testing.T) {
+	country := "GB"
+	attributes := &models.AccountAttributes{Country: &country, BankID: "123456", AccountNumber: "98765432", Bic: "WESTGB22"}
+
+	got, err := FromAttributes(attributes)
+
+	if err != nil || got != "GB82WEST12345698765432" {
+		t.Errorf("wanted: GB82WEST12345698765432\n got: %s %v", got, err)
+	}
+
+	if _, err := FromAttributes(&models.AccountAttributes{}); !errors.Is(err, ErrInvalidComponent) {
+		t.Errorf("wanted: %v\n got: %v", ErrInvalidComponent, err)
+	}
+}
+
+func TestIban_ShouldComputeCheckDigits(t *testing.T) {
+	got, err := CheckDigits("GB", "WEST12345698765432")
+
+	if err != nil || got != "82" {
+		t.Errorf("wanted: 82\n got: %s %v", got, err)
+	}
+}
+
+func TestIban_ShouldValidateIban(t *testing.T) {
+	dataTable := []struct {
+		testName string
+		iban     string
+		want     error
+	}{
+		{"electronic", "GB82WEST12345698765432", nil},
+		{"print", "gb82 west 1234 5698 7654 32", nil},
+		{"otherCountry", "CH9300762011623852957", nil},
+		{"wrongCheckDigits", "GB83WEST12345698765432", ErrInvalidChecksum},
+		{"wrongLength", "GB82WEST1234569876543", ErrInvalidLength},
+		{"unknownCountry", "XX82WEST12345698765432", ErrUnsupportedCountry},
+		{"invalidCharacters", "GB82WEST-12345698765432", ErrInvalidFormat},
+		{"empty", "", ErrInvalidFormat},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			got := Validate(v.iban)
+
+			if !errors.Is(got, v.want) {
+				t.Errorf("wanted: %v\n got: %v", v.want, got)
+			}
+		})
+	}
+}
+
+func TestIban_ShouldFormatIban(t *testing.T) {
+	if got := Electronic(" gb82 West 1234 5698 7654 32 "); got != "GB82WEST12345698765432" {
+		t.Errorf("electronic wanted: GB82WEST12345698765432\n got: %s", got)
+	}
+
+	if got := Print("GB82WEST12345698765432"); got != "GB82 WEST 1234 5698 7654 32" {
+		t.Errorf("print wanted: GB82 WEST 1234 5698 7654 32\n got: %s", got)
+	}
+
+	if got := Print("BE68539007547034"); got != "BE68 5390 0754 7034" {
+		t.Errorf("print wanted: BE68 5390 0754 7034\n got: %s", got)
+	}
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type stdLogger struct {
+	logger   *log.Logger
+	minLevel Level
+}
+
+// NewStdLogger writes entries through a standard log.Logger in logfmt style, e.g.
+// level=info msg="request completed" operation=Fetch status=200. Entries below minLevel are discarded.
+func NewStdLogger(logger *log.Logger, minLevel Level) Logger {
+	return &stdLogger{logger: logger, minLevel: minLevel}
+}
+
+func (s *stdLogger) Enabled(level Level) bool {
+	return level >= s.minLevel
+}
+
+func (s *stdLogger) Log(level Level, msg string, fields ...Field) {
+	if !s.Enabled(level) {
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString("level=")
+	line.WriteString(level.String())
+	line.WriteString(" msg=")
+	line.WriteString(logfmtValue(msg))
+
+	for _, field := range fields {
+		line.WriteString(" ")
+		line.WriteString(field.Key)
+		line.WriteString("=")
+		line.WriteString(logfmtValue(fmt.Sprint(field.Value)))
+	}
+
+	s.logger.Print(line.String())
+}
+
+// logfmtValue quotes values containing spaces, quotes or equal signs, so every entry can be parsed back.
+func logfmtValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
+
+type jsonLogger struct {
+	mu       sync.Mutex
+	writer   io.Writer
+	minLevel Level
+	now      func() time.Time
+}
+
+// NewJSONLogger writes every entry as a JSON object in its own line, with time, level, msg and one
+// key per field. Entries below minLevel are discarded. It is safe for concurrent use.
+func NewJSONLogger(writer io.Writer, minLevel Level) Logger {
+	return &jsonLogger{writer: writer, minLevel: minLevel, now: time.Now}
+}
+
+func (j *jsonLogger) Enabled(level Level) bool {
+	return level >= j.minLevel
+}
+
+func (j *jsonLogger) Log(level Level, msg string, fields ...Field) {
+	if !j.Enabled(level) {
+		return
+	}
+
+	entry := make(map[string]interface{}, len(fields)+3)
+	for _, field := range fields {
+		entry[field.Key] = jsonValue(field.Value)
+	}
+	entry["time"] = j.now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = msg
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		line, _ = json.Marshal(map[string]string{"level": LevelError.String(), "msg": "failed encoding log entry: " + err.Error()})
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, _ = j.writer.Write(append(line, '\n'))
+}
+
+// jsonValue converts values that do not encode well, such as errors and durations, into strings.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStdLogger_ShouldWriteLogfmtFields(t *testing.T) {
+	var out bytes.Buffer
+	subject := NewStdLogger(log.New(&out, "", 0), LevelInfo)
+
+	subject.Log(LevelInfo, "request completed", F(FieldOperation, "Fetch"), F(FieldStatus, 200), F(FieldError, "not found here"))
+
+	want := `level=info msg="request completed" operation=Fetch status=200 error="not found here"` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("wanted: %q\n got: %q", want, got)
+	}
+}
+
+func TestStdLogger_ShouldDiscardLevelsBelowMinimum(t *testing.T) {
+	var out bytes.Buffer
+	subject := NewStdLogger(log.New(&out, "", 0), LevelWarn)
+
+	subject.Log(LevelInfo, "request completed")
+
+	if subject.Enabled(LevelDebug) || !subject.Enabled(LevelError) {
+		t.Errorf("wanted: only warn and error enabled")
+	}
+	if out.Len() != 0 {
+		t.Errorf("wanted: nothing written\n got: %q", out.String())
+	}
+}
+
+func TestJSONLogger_ShouldWriteOneObjectPerLine(t *testing.T) {
+	var out bytes.Buffer
+	subject := NewJSONLogger(&out, LevelDebug).(*jsonLogger)
+	subject.now = func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	subject.Log(LevelDebug, "request failed", F(FieldAttempt, 2), F(FieldError, errors.New("connection reset")))
+	subject.Log(LevelError, "second")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wanted: 2 lines\n got: %d", len(lines))
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("wanted: JSON line\n got: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"time":    "2021-01-02T03:04:05Z",
+		"level":   "debug",
+		"msg":     "request failed",
+		"attempt": float64(2),
+		"error":   "connection reset",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %v\n got: %v", want, got)
+	}
+}
+
+func TestFields_ShouldBeAppendedThroughContext(t *testing.T) {
+	ctx := WithFields(context.Background(), F(FieldOperation, "Create"))
+	ctx = WithFields(ctx, F(FieldAttempt, 1))
+
+	want := []Field{F(FieldOperation, "Create"), F(FieldAttempt, 1)}
+	if got := FieldsFrom(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %v\n got: %v", want, got)
+	}
+
+	if got := FieldsFrom(context.Background()); got != nil {
+		t.Errorf("wanted: nil\n got: %v", got)
+	}
+}
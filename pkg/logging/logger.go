@@ -0,0 +1,94 @@
+// Package logging defines the Logger used by this library and adapters for the standard log package and
+// JSON lines. Entries are made of a message and structured fields, and loggers expose the levels they
+// accept, so expensive output is only formatted when it is going to be written.
+package logging
+
+import (
+	"context"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Keys of the fields added to every request logged by this library.
+const (
+	FieldOperation = "operation"
+	FieldAccountID = "account_id"
+	FieldMethod    = "method"
+	FieldURL       = "url"
+	FieldStatus    = "status"
+	FieldLatency   = "latency_ms"
+	FieldAttempt   = "attempt"
+	FieldError     = "error"
+)
+
+// Field is a key/value pair attached to a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F is a shorthand to build a Field.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Logger writes structured entries. Enabled allows callers to skip building entries of levels that would
+// be discarded.
+type Logger interface {
+	Enabled(level Level) bool
+	Log(level Level, msg string, fields ...Field)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Enabled(Level) bool { return false }
+
+func (nopLogger) Log(Level, string, ...Field) {}
+
+// Nop returns a Logger that discards everything.
+func Nop() Logger {
+	return nopLogger{}
+}
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying fields, they are appended to the ones already in ctx. This
+// library uses it to pass the operation, account ID and attempt of a request down to the logging transport.
+func WithFields(ctx context.Context, fields ...Field) context.Context {
+	current := FieldsFrom(ctx)
+
+	merged := make([]Field, 0, len(current)+len(fields))
+	merged = append(merged, current...)
+	merged = append(merged, fields...)
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFrom returns the fields carried by ctx.
+func FieldsFrom(ctx context.Context) []Field {
+	fields, _ := ctx.Value(fieldsKey{}).([]Field)
+	return fields
+}
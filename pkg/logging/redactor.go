@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces every value that is masked by a Redactor.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactedFields are the JSON paths of account data that identify a customer, they apply to
+// single accounts and to every account of a list, as arrays are traversed transparently.
+var DefaultRedactedFields = []string{
+	"data.attributes.name",
+	"data.attributes.alternative_names",
+	"data.attributes.iban",
+	"data.attributes.account_number",
+	"data.attributes.secondary_identification",
+}
+
+// DefaultRedactedHeaders are headers carrying credentials.
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Signature",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// Redactor masks sensitive fields of JSON bodies, headers and query parameters before they are logged.
+type Redactor struct {
+	fieldPaths [][]string
+	fieldNames map[string]bool
+	headers    map[string]bool
+}
+
+// NewRedactor builds a Redactor from JSON paths and header names. A path is made of object keys separated
+// by dots, "*" matches any key, e.g. "data.attributes.iban" or "*.iban". Query parameters named after the
+// last key of a path, e.g. filter[iban], are masked as well. Header names are case-insensitive.
+func NewRedactor(fieldPaths []string, headers []string) *Redactor {
+	r := &Redactor{
+		fieldNames: make(map[string]bool, len(fieldPaths)),
+		headers:    make(map[string]bool, len(headers)),
+	}
+
+	for _, path := range fieldPaths {
+		segments := strings.Split(path, ".")
+		r.fieldPaths = append(r.fieldPaths, segments)
+		r.fieldNames[segments[len(segments)-1]] = true
+	}
+
+	for _, header := range headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return r
+}
+
+// DefaultRedactor masks DefaultRedactedFields and DefaultRedactedHeaders.
+func DefaultRedactor() *Redactor {
+	return NewRedactor(DefaultRedactedFields, DefaultRedactedHeaders)
+}
+
+// Body returns body with the configured fields masked. A body that is not JSON is masked as a whole,
+// since it cannot be told whether it contains sensitive data.
+func (r *Redactor) Body(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 || len(r.fieldPaths) == 0 {
+		return string(body)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return Redacted
+	}
+
+	for _, path := range r.fieldPaths {
+		value = redactValue(value, path)
+	}
+
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return Redacted
+	}
+
+	return string(redacted)
+}
+
+// Header returns a copy of header with the configured headers masked.
+func (r *Redactor) Header(header http.Header) http.Header {
+	redacted := header.Clone()
+
+	for key, values := range redacted {
+		if r.headers[http.CanonicalHeaderKey(key)] {
+			masked := make([]string, len(values))
+			for i := range masked {
+				masked[i] = Redacted
+			}
+			redacted[key] = masked
+		}
+	}
+
+	return redacted
+}
+
+// URL returns u as a string, with query parameters named after a configured field masked.
+func (r *Redactor) URL(u *url.URL) string {
+	if u.RawQuery == "" || len(r.fieldNames) == 0 {
+		return u.String()
+	}
+
+	query := u.Query()
+	for key := range query {
+		if r.isRedactedParam(key) {
+			query.Set(key, Redacted)
+		}
+	}
+
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+
+	return redacted.String()
+}
+
+// isRedactedParam supports plain names, e.g. iban, and JSON:API filters, e.g. filter[iban].
+func (r *Redactor) isRedactedParam(key string) bool {
+	if start := strings.LastIndex(key, "["); start >= 0 && strings.HasSuffix(key, "]") {
+		key = key[start+1 : len(key)-1]
+	}
+
+	return r.fieldNames[key]
+}
+
+func redactValue(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i], path)
+		}
+	case map[string]interface{}:
+		for key, child := range v {
+			if path[0] != "*" && path[0] != key {
+				continue
+			}
+
+			if len(path) == 1 {
+				v[key] = Redacted
+			} else {
+				v[key] = redactValue(child, path[1:])
+			}
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestRedactor_ShouldMaskDefaultFieldsOfSingleAccountAndList(t *testing.T) {
+	dataTable := []struct {
+		testName string
+		body     string
+		want     string
+	}{
+		{
+			"singleAccount",
+			`{"data":{"id":"1","attributes":{"country":"GB","name":["Samantha Holder"],"iban":"GB82WEST12345698765432","version":0}}}`,
+			`{"data":{"attributes":{"country":"GB","iban":"[REDACTED]","name":"[REDACTED]","version":0},"id":"1"}}`,
+		},
+		{
+			"list",
+			`{"data":[{"attributes":{"account_number":"41426819","bic":"NWBKGB42"}},{"attributes":{"secondary_identification":"A1B2C3D4"}}]}`,
+			`{"data":[{"attributes":{"account_number":"[REDACTED]","bic":"NWBKGB42"}},{"attributes":{"secondary_identification":"[REDACTED]"}}]}`,
+		},
+		{"errorMessage", `{"error_message":"id is not a valid uuid"}`, `{"error_message":"id is not a valid uuid"}`},
+		{"empty", ``, ``},
+		{"nonJSON", `name=Samantha`, Redacted},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			got := DefaultRedactor().Body([]byte(v.body))
+
+			if got != v.want {
+				t.Errorf("wanted: %s\n got: %s", v.want, got)
+			}
+		})
+	}
+}
+
+func TestRedactor_ShouldSupportWildcardPaths(t *testing.T) {
+	subject := NewRedactor([]string{"*.secret"}, nil)
+
+	got := subject.Body([]byte(`{"a":{"secret":1,"public":2},"b":{"secret":[3]}}`))
+
+	want := `{"a":{"public":2,"secret":"[REDACTED]"},"b":{"secret":"[REDACTED]"}}`
+	if got != want {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+}
+
+func TestRedactor_ShouldMaskHeadersWithoutModifyingThem(t *testing.T) {
+	header := http.Header{
+		"Authorization": {"Signature keyId=\"key\""},
+		"Date":          {"Mon, 02 Jan 2006 15:04:05 GMT"},
+	}
+
+	got := NewRedactor(nil, []string{"authorization"}).Header(header)
+
+	want := http.Header{
+		"Authorization": {Redacted},
+		"Date":          {"Mon, 02 Jan 2006 15:04:05 GMT"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %v\n got: %v", want, got)
+	}
+	if header.Get("Authorization") == Redacted {
+		t.Errorf("wanted: original header untouched")
+	}
+}
+
+func TestRedactor_ShouldMaskQueryParameters(t *testing.T) {
+	u, _ := url.Parse("http://localhost:8080/v1/organisation/accounts?filter%5Biban%5D=GB82WEST12345698765432&filter%5Bcountry%5D=GB")
+
+	got := DefaultRedactor().URL(u)
+
+	want := "http://localhost:8080/v1/organisation/accounts?filter%5Bcountry%5D=GB&filter%5Biban%5D=%5BREDACTED%5D"
+	if got != want {
+		t.Errorf("wanted: %s\n got: %s", want, got)
+	}
+}
@@ -0,0 +1,224 @@
+// Package metrics provides a MetricsRecorder that keeps measurements of the account API operations in memory
+// and renders them in Prometheus text exposition format, without depending on the Prometheus client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	requestsTotalName   = "accountapi_requests_total"
+	requestDurationName = "accountapi_request_duration_seconds"
+	retriesTotalName    = "accountapi_retries_total"
+	inFlightName        = "accountapi_requests_in_flight"
+	queueDurationName   = "accountapi_request_queue_seconds"
+	contentType         = "text/plain; version=0.0.4; charset=utf-8"
+	statusClassError    = "error"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histogram. They cover the default timeout
+// of 4 seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type requestKey struct {
+	operation   string
+	statusClass string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// PrometheusRecorder counts requests per operation and status code class (2xx, 4xx, 5xx or error when no
+// response was received), retries per operation, requests in flight and histograms per operation of latency and
+// time spent waiting for the rate limit and concurrency cap.
+// It is an http.Handler serving the metrics, so it can be mounted on the /metrics endpoint of a service.
+type PrometheusRecorder struct {
+	mu        sync.Mutex
+	buckets   []float64
+	requests  map[requestKey]uint64
+	latencies map[string]*histogram
+	queued    map[string]*histogram
+	retries   map[string]uint64
+	inFlight  map[string]int64
+}
+
+// NewPrometheusRecorder creates a recorder whose histogram uses buckets, DefaultBuckets are used when none is given.
+func NewPrometheusRecorder(buckets ...float64) *PrometheusRecorder {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &PrometheusRecorder{
+		buckets:   sorted,
+		requests:  map[requestKey]uint64{},
+		latencies: map[string]*histogram{},
+		queued:    map[string]*histogram{},
+		retries:   map[string]uint64{},
+		inFlight:  map[string]int64{},
+	}
+}
+
+func (p *PrometheusRecorder) RequestStarted(operation string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inFlight[operation]++
+}
+
+func (p *PrometheusRecorder) RequestFinished(operation string, statusCode int, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inFlight[operation]--
+	p.requests[requestKey{operation: operation, statusClass: statusClass(statusCode)}]++
+	p.observe(p.latencies, operation, latency)
+}
+
+func (p *PrometheusRecorder) RequestQueued(operation string, waited time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.observe(p.queued, operation, waited)
+}
+
+// observe adds a duration to the histogram of operation, it must be invoked holding the lock.
+func (p *PrometheusRecorder) observe(histograms map[string]*histogram, operation string, duration time.Duration) {
+	h, ok := histograms[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		histograms[operation] = h
+	}
+
+	seconds := duration.Seconds()
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (p *PrometheusRecorder) RetryAttempted(operation string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.retries[operation]++
+}
+
+// ServeHTTP writes every metric in Prometheus text exposition format.
+func (p *PrometheusRecorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	_ = p.Write(w)
+}
+
+// Write renders every metric in Prometheus text exposition format, series are sorted, so the output is stable.
+func (p *PrometheusRecorder) Write(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := bufio.NewWriter(w)
+
+	writeHeader(out, requestsTotalName, "counter", "Requests sent to the account API by operation and status code class.")
+	requestKeys := make([]requestKey, 0, len(p.requests))
+	for key := range p.requests {
+		requestKeys = append(requestKeys, key)
+	}
+	sort.Slice(requestKeys, func(i, j int) bool {
+		if requestKeys[i].operation != requestKeys[j].operation {
+			return requestKeys[i].operation < requestKeys[j].operation
+		}
+		return requestKeys[i].statusClass < requestKeys[j].statusClass
+	})
+	for _, key := range requestKeys {
+		fmt.Fprintf(out, "%s{operation=%s,status_class=%s} %d\n", requestsTotalName, quote(key.operation), quote(key.statusClass), p.requests[key])
+	}
+
+	writeHeader(out, requestDurationName, "histogram", "Latency of the account API operations, including retries.")
+	p.writeHistograms(out, requestDurationName, p.latencies)
+
+	writeHeader(out, retriesTotalName, "counter", "Retries of requests sent to the account API by operation.")
+	for _, operation := range sortedKeys(p.retries) {
+		fmt.Fprintf(out, "%s{operation=%s} %d\n", retriesTotalName, quote(operation), p.retries[operation])
+	}
+
+	writeHeader(out, inFlightName, "gauge", "Requests to the account API waiting for a response by operation.")
+	for _, operation := range sortedKeys(p.inFlight) {
+		fmt.Fprintf(out, "%s{operation=%s} %d\n", inFlightName, quote(operation), p.inFlight[operation])
+	}
+
+	writeHeader(out, queueDurationName, "histogram", "Time the account API operations waited for the rate limit and concurrency cap.")
+	p.writeHistograms(out, queueDurationName, p.queued)
+
+	return out.Flush()
+}
+
+func (p *PrometheusRecorder) writeHistograms(out io.Writer, name string, histograms map[string]*histogram) {
+	for _, operation := range sortedKeys(histograms) {
+		h := histograms[operation]
+		for i, bound := range p.buckets {
+			fmt.Fprintf(out, "%s_bucket{operation=%s,le=%s} %d\n", name, quote(operation), quote(formatFloat(bound)), h.counts[i])
+		}
+		fmt.Fprintf(out, "%s_bucket{operation=%s,le=\"+Inf\"} %d\n", name, quote(operation), h.count)
+		fmt.Fprintf(out, "%s_sum{operation=%s} %s\n", name, quote(operation), formatFloat(h.sum))
+		fmt.Fprintf(out, "%s_count{operation=%s} %d\n", name, quote(operation), h.count)
+	}
+}
+
+func writeHeader(out io.Writer, name string, metricType string, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// sortedKeys returns the operations of a map of metrics sorted alphabetically.
+func sortedKeys(metrics interface{}) []string {
+	var keys []string
+
+	switch m := metrics.(type) {
+	case map[string]*histogram:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[string]uint64:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[string]int64:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func statusClass(statusCode int) string {
+	if statusCode < 100 || statusCode > 599 {
+		return statusClassError
+	}
+
+	return strconv.Itoa(statusCode/100) + "xx"
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// quote escapes a label value as defined by the text exposition format.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
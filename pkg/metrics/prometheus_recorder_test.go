@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPrometheusRecorder_ShouldRenderTextExposition(t *testing.T) {
+	subject := NewPrometheusRecorder(0.1, 0.01)
+
+	subject.RequestStarted("Fetch")
+	subject.RequestFinished("Fetch", 200, 5*time.Millisecond)
+	subject.RequestStarted("Fetch")
+	subject.RequestFinished("Fetch", 404, 50*time.Millisecond)
+	subject.RequestStarted("Create")
+	subject.RetryAttempted("Create")
+	subject.RequestFinished("Create", 0, 200*time.Millisecond)
+	subject.RequestQueued("Delete", 20*time.Millisecond)
+	subject.RequestStarted("Delete")
+
+	var out strings.Builder
+	_ = subject.Write(&out)
+
+	want := `# HELP accountapi_requests_total Requests sent to the account API by operation and status code class.
+# TYPE accountapi_requests_total counter
+accountapi_requests_total{operation="Create",status_class="error"} 1
+accountapi_requests_total{operation="Fetch",status_class="2xx"} 1
+accountapi_requests_total{operation="Fetch",status_class="4xx"} 1
+# HELP accountapi_request_duration_seconds Latency of the account API operations, including retries.
+# TYPE accountapi_request_duration_seconds histogram
+accountapi_request_duration_seconds_bucket{operation="Create",le="0.01"} 0
+accountapi_request_duration_seconds_bucket{operation="Create",le="0.1"} 0
+accountapi_request_duration_seconds_bucket{operation="Create",le="+Inf"} 1
+accountapi_request_duration_seconds_sum{operation="Create"} 0.2
+accountapi_request_duration_seconds_count{operation="Create"} 1
+accountapi_request_duration_seconds_bucket{operation="Fetch",le="0.01"} 1
+accountapi_request_duration_seconds_bucket{operation="Fetch",le="0.1"} 2
+accountapi_request_duration_seconds_bucket{operation="Fetch",le="+Inf"} 2
+accountapi_request_duration_seconds_sum{operation="Fetch"} 0.055
+accountapi_request_duration_seconds_count{operation="Fetch"} 2
+# HELP accountapi_retries_total Retries of requests sent to the account API by operation.
+# TYPE accountapi_retries_total counter
+accountapi_retries_total{operation="Create"} 1
+# HELP accountapi_requests_in_flight Requests to the account API waiting for a response by operation.
+# TYPE accountapi_requests_in_flight gauge
+accountapi_requests_in_flight{operation="Create"} 0
+accountapi_requests_in_flight{operation="Delete"} 1
+accountapi_requests_in_flight{operation="Fetch"} 0
+# HELP accountapi_request_queue_seconds Time the account API operations waited for the rate limit and concurrency cap.
+# TYPE accountapi_request_queue_seconds histogram
+accountapi_request_queue_seconds_bucket{operation="Delete",le="0.01"} 0
+accountapi_request_queue_seconds_bucket{operation="Delete",le="0.1"} 1
+accountapi_request_queue_seconds_bucket{operation="Delete",le="+Inf"} 1
+accountapi_request_queue_seconds_sum{operation="Delete"} 0.02
+accountapi_request_queue_seconds_count{operation="Delete"} 1
+`
+	if got := out.String(); got != want {
+		t.Errorf("wanted:\n%s\n got:\n%s", want, got)
+	}
+}
+
+func TestPrometheusRecorder_ShouldServeMetrics(t *testing.T) {
+	subject := NewPrometheusRecorder()
+	subject.RequestStarted("List")
+	subject.RequestFinished("List", 503, time.Second)
+
+	recorder := httptest.NewRecorder()
+	subject.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := recorder.Header().Get("Content-Type"); got != contentType {
+		t.Errorf("content type wanted: %s\n got: %s", contentType, got)
+	}
+	if got := recorder.Body.String(); !strings.Contains(got, `accountapi_requests_total{operation="List",status_class="5xx"} 1`) {
+		t.Errorf("wanted: List 5xx counter\n got: %s", got)
+	}
+}
+
+func TestPrometheusRecorder_ShouldBeSafeForConcurrentUse(t *testing.T) {
+	subject := NewPrometheusRecorder()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subject.RequestStarted("Fetch")
+			subject.RetryAttempted("Fetch")
+			subject.RequestFinished("Fetch", 200, time.Millisecond)
+			_ = subject.Write(&strings.Builder{})
+		}()
+	}
+	wg.Wait()
+
+	var out strings.Builder
+	_ = subject.Write(&out)
+	if !strings.Contains(out.String(), `accountapi_requests_total{operation="Fetch",status_class="2xx"} 50`) {
+		t.Errorf("wanted: 50 requests\n got: %s", out.String())
+	}
+}
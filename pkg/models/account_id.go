@@ -0,0 +1,16 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// NewAccountID returns a random (version 4) UUID, which can be used as the ID of a new account.
+func NewAccountID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
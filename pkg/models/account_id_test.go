@@ -0,0 +1,11 @@
+package models
+
+import "testing"
+
+func TestNewAccountID_ShouldReturnRandomVersion4UUID(t *testing.T) {
+	got := NewAccountID()
+
+	if !uuidPattern.MatchString(got) || got[14] != '4' || got == NewAccountID() {
+		t.Errorf("wanted: random version 4 UUID\n got: %s", got)
+	}
+}
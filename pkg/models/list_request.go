@@ -0,0 +1,17 @@
+package models
+
+type ListRequest struct {
+	PageNumber int
+	PageSize   int
+	Filter     *ListFilter
+}
+
+// ListFilter holds filter[...] query parameters supported by the account API, empty values are not sent.
+type ListFilter struct {
+	BankID        string
+	BankIDCode    string
+	AccountNumber string
+	Iban          string
+	Country       string
+	CustomerID    string
+}
@@ -0,0 +1,11 @@
+package models
+
+type ListResponse struct {
+	ResBody    *ListResponseObject
+	StatusCode int
+}
+
+type ListResponseObject struct {
+	Data  []ResponseData `json:"data"`
+	Links *Link          `json:"links,omitempty"`
+}
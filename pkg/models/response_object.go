@@ -18,5 +18,9 @@ type ResponseData struct {
 }
 
 type Link struct {
-	Self string `json:"self,omitempty"`
+	Self  string `json:"self,omitempty"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
 }
@@ -0,0 +1,7 @@
+package models
+
+// UpdateRequest holds the attributes to modify, Data.ID identifies the account and Data.Version
+// has to be the current version of the account, otherwise the account API reports a conflict.
+type UpdateRequest struct {
+	Data *AccountData `json:"data,omitempty"`
+}
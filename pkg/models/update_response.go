@@ -0,0 +1,6 @@
+package models
+
+type UpdateResponse struct {
+	ResBody    *ResponseObject
+	StatusCode int
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	accountsType           = "accounts"
+	maxNames               = 4
+	maxAlternativeNames    = 3
+	maxNameLength          = 140
+	maxSecondaryIdLength   = 140
+	msgRequired            = "is required"
+	msgInvalidUUID         = "must be a valid UUID"
+	msgInvalidType         = "must be " + accountsType
+	msgInvalidCountry      = "must be an ISO 3166-1 alpha-2 country code"
+	msgInvalidCurrency     = "must be an ISO 4217 currency code"
+	msgInvalidBic          = "must be a valid BIC (SWIFT code)"
+	msgInvalidClass        = "must be Personal or Business"
+	msgTooManyItemsFormat  = "must have at most %d items"
+	msgItemTooLongFormat   = "must have at most %d characters"
+	msgItemEmpty           = "must not be empty"
+	classificationPersonal = "Personal"
+	classificationBusiness = "Business"
+)
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	bicPattern  = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// FieldError describes why a field is invalid, Field is the JSON path of the field, e.g. data.attributes.country
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (f FieldError) Error() string {
+	return f.Field + ": " + f.Message
+}
+
+// ValidationErrors is the structured list of field errors returned by Validate methods, it can be
+// obtained from any error returned by the library with errors.As.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, fieldErr := range v {
+		messages = append(messages, fieldErr.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// orNil avoids returning a non-nil error interface holding an empty list
+func (v ValidationErrors) orNil() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+// Validate checks the request before sending it to the account API, it returns ValidationErrors when
+// there is at least one invalid field.
+func (c *CreateRequest) Validate() error {
+	if c.Data == nil {
+		return ValidationErrors{{Field: "data", Message: msgRequired}}
+	}
+
+	return c.Data.validate("data", nil).orNil()
+}
+
+// Validate checks ID and organisation ID format, type and attributes, it returns ValidationErrors when
+// there is at least one invalid field.
+func (a *AccountData) Validate() error {
+	return a.validate("", nil).orNil()
+}
+
+// Validate checks country, base currency, BIC, names and classification, it returns ValidationErrors when
+// there is at least one invalid field.
+func (a *AccountAttributes) Validate() error {
+	return a.validate("", nil).orNil()
+}
+
+func (a *AccountData) validate(path string, errs ValidationErrors) ValidationErrors {
+	if !uuidPattern.MatchString(a.ID) {
+		errs = append(errs, FieldError{Field: fieldPath(path, "id"), Message: msgInvalidUUID})
+	}
+
+	if !uuidPattern.MatchString(a.OrganisationID) {
+		errs = append(errs, FieldError{Field: fieldPath(path, "organisation_id"), Message: msgInvalidUUID})
+	}
+
+	if a.Type != accountsType {
+		errs = append(errs, FieldError{Field: fieldPath(path, "type"), Message: msgInvalidType})
+	}
+
+	if a.Attributes == nil {
+		return append(errs, FieldError{Field: fieldPath(path, "attributes"), Message: msgRequired})
+	}
+
+	return a.Attributes.validate(fieldPath(path, "attributes"), errs)
+}
+
+func (a *AccountAttributes) validate(path string, errs ValidationErrors) ValidationErrors {
+	switch {
+	case a.Country == nil || *a.Country == "":
+		errs = append(errs, FieldError{Field: fieldPath(path, "country"), Message: msgRequired})
+	case !IsCountryCode(*a.Country):
+		errs = append(errs, FieldError{Field: fieldPath(path, "country"), Message: msgInvalidCountry})
+	}
+
+	if a.BaseCurrency != "" && !IsCurrencyCode(a.BaseCurrency) {
+		errs = append(errs, FieldError{Field: fieldPath(path, "base_currency"), Message: msgInvalidCurrency})
+	}
+
+	if a.Bic != "" && !bicPattern.MatchString(a.Bic) {
+		errs = append(errs, FieldError{Field: fieldPath(path, "bic"), Message: msgInvalidBic})
+	}
+
+	errs = validateNames(fieldPath(path, "name"), a.Name, maxNames, errs)
+	errs = validateNames(fieldPath(path, "alternative_names"), a.AlternativeNames, maxAlternativeNames, errs)
+
+	if len(a.SecondaryIdentification) > maxSecondaryIdLength {
+		errs = append(errs, FieldError{
+			Field:   fieldPath(path, "secondary_identification"),
+			Message: fmt.Sprintf(msgItemTooLongFormat, maxSecondaryIdLength),
+		})
+	}
+
+	if c := a.AccountClassification; c != nil && *c != classificationPersonal && *c != classificationBusiness {
+		errs = append(errs, FieldError{Field: fieldPath(path, "account_classification"), Message: msgInvalidClass})
+	}
+
+	return errs
+}
+
+func validateNames(path string, names []string, maxItems int, errs ValidationErrors) ValidationErrors {
+	if len(names) > maxItems {
+		errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf(msgTooManyItemsFormat, maxItems)})
+	}
+
+	for i, name := range names {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case strings.TrimSpace(name) == "":
+			errs = append(errs, FieldError{Field: itemPath, Message: msgItemEmpty})
+		case len([]rune(name)) > maxNameLength:
+			errs = append(errs, FieldError{Field: itemPath, Message: fmt.Sprintf(msgItemTooLongFormat, maxNameLength)})
+		}
+	}
+
+	return errs
+}
+
+func fieldPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+
+	return path + "." + field
+}
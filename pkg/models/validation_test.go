@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getValidRequest() *CreateRequest {
+	country, classification := "GB", "Personal"
+	return &CreateRequest{
+		Data: &AccountData{
+			ID:             "ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6",
+			OrganisationID: "ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6",
+			Type:           "accounts",
+			Attributes: &AccountAttributes{
+				AccountClassification: &classification,
+				BaseCurrency:          "GBP",
+				Bic:                   "NWBKGB42",
+				Country:               &country,
+				Name:                  []string{"Samantha Holder"},
+				AlternativeNames:      []string{"Sam Holder"},
+			},
+		},
+	}
+}
+
+func TestValidation_ShouldAcceptValidRequest(t *testing.T) {
+	if err := getValidRequest().Validate(); err != nil {
+		t.Errorf("wanted: nil\n got: %v", err)
+	}
+}
+
+func TestValidation_ShouldRequireData(t *testing.T) {
+	want := ValidationErrors{{Field: "data", Message: msgRequired}}
+
+	got := (&CreateRequest{}).Validate()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %v\n got: %v", want, got)
+	}
+}
+
+func TestValidation_ShouldReportEveryInvalidField(t *testing.T) {
+	country, classification := "UK", "Premium"
+	subject := getValidRequest()
+	subject.Data.ID = "123"
+	subject.Data.Type = "account"
+	subject.Data.Attributes.Country = &country
+	subject.Data.Attributes.BaseCurrency = "GBX"
+	subject.Data.Attributes.Bic = "NWBK42"
+	subject.Data.Attributes.AccountClassification = &classification
+	subject.Data.Attributes.Name = []string{"a", "b", "c", "d", ""}
+	subject.Data.Attributes.AlternativeNames = []string{strings.Repeat("x", 141)}
+
+	want := []string{
+		"data.id",
+		"data.type",
+		"data.attributes.country",
+		"data.attributes.base_currency",
+		"data.attributes.bic",
+		"data.attributes.name",
+		"data.attributes.name[4]",
+		"data.attributes.alternative_names[0]",
+		"data.attributes.account_classification",
+	}
+
+	err := subject.Validate()
+
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("wanted: ValidationErrors\n got: %v", err)
+	}
+	var got []string
+	for _, fieldErr := range validationErrs {
+		got = append(got, fieldErr.Field)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %v\n got: %v", want, got)
+	}
+}
+
+func TestValidation_ShouldValidateAttributesWithoutPath(t *testing.T) {
+	want := "country: is required"
+
+	got := (&AccountAttributes{}).Validate()
+
+	if got == nil || got.Error() != want {
+		t.Errorf("wanted: %s\n got: %v", want, got)
+	}
+}
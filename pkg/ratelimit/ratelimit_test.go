@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// getBucketStub returns a bucket whose clock only moves when the returned function is invoked.
+func getBucketStub(rate float64, burst int) (*TokenBucket, func(time.Duration)) {
+	now := time.Date(2021, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	bucket := NewTokenBucket(rate, burst)
+	bucket.now = func() time.Time { return now }
+	bucket.last = now
+
+	return bucket, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestTokenBucket_ShouldAllowBurstAndThenSpaceReservations(t *testing.T) {
+	subject, advance := getBucketStub(10, 2)
+
+	got := []time.Duration{subject.reserve(), subject.reserve(), subject.reserve(), subject.reserve()}
+
+	want := []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("reservation %d wanted: %v\n got: %v", i, want[i], got[i])
+		}
+	}
+
+	advance(time.Second)
+	if delay := subject.reserve(); delay != 0 {
+		t.Errorf("wanted: refilled bucket\n got: %v", delay)
+	}
+}
+
+func TestTokenBucket_ShouldGiveTokenBackWhenContextIsDone(t *testing.T) {
+	subject, _ := getBucketStub(1, 1)
+	_ = subject.reserve()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := subject.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wanted: %v\n got: %v", context.DeadlineExceeded, err)
+	}
+	if subject.tokens != 0 {
+		t.Errorf("tokens wanted: 0\n tokens got: %v", subject.tokens)
+	}
+}
+
+func TestTokenBucket_ShouldWaitForToken(t *testing.T) {
+	subject := NewTokenBucket(100, 1)
+
+	start := time.Now()
+	_ = subject.Wait(context.Background())
+	_ = subject.Wait(context.Background())
+
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Errorf("wanted: second wait delayed around 10ms\n got: %v", elapsed)
+	}
+}
+
+func TestSemaphore_ShouldCapHolders(t *testing.T) {
+	subject := NewSemaphore(2)
+	_ = subject.Acquire(context.Background())
+	_ = subject.Acquire(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := subject.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wanted: %v\n got: %v", context.DeadlineExceeded, err)
+	}
+
+	subject.Release()
+	if err := subject.Acquire(context.Background()); err != nil {
+		t.Errorf("wanted: slot after release\n got: %v", err)
+	}
+}
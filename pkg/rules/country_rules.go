@@ -0,0 +1,219 @@
+// Package rules holds the country-specific rules of the account API for bank ID, bank ID code, BIC,
+// account number and IBAN, and validates account attributes against them.
+package rules
+
+import (
+	"accountapi-lib-form3/pkg/models"
+	"regexp"
+	"sort"
+)
+
+// Requirement tells whether a field has to be sent for a given country.
+type Requirement int
+
+const (
+	Optional Requirement = iota
+	Required
+	Forbidden
+)
+
+func (r Requirement) String() string {
+	switch r {
+	case Required:
+		return "required"
+	case Forbidden:
+		return "forbidden"
+	default:
+		return "optional"
+	}
+}
+
+// FieldRule defines the requirement of a field and, when it is sent, the format it has to match.
+type FieldRule struct {
+	Requirement Requirement
+	Pattern     *regexp.Regexp
+	Format      string
+}
+
+// CountryRule groups the rules of every country-specific field of AccountAttributes.
+type CountryRule struct {
+	Country       string
+	BankID        FieldRule
+	BankIDCode    FieldRule
+	Bic           FieldRule
+	AccountNumber FieldRule
+	Iban          FieldRule
+}
+
+// Fields returns the rules indexed by the JSON name of the field.
+func (c CountryRule) Fields() map[string]FieldRule {
+	return map[string]FieldRule{
+		"bank_id":        c.BankID,
+		"bank_id_code":   c.BankIDCode,
+		"bic":            c.Bic,
+		"account_number": c.AccountNumber,
+		"iban":           c.Iban,
+	}
+}
+
+var (
+	bicRule       = FieldRule{Requirement: Required, Pattern: regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`), Format: "8 or 11 character BIC"}
+	optionalBic   = FieldRule{Requirement: Optional, Pattern: bicRule.Pattern, Format: bicRule.Format}
+	ibanRule      = FieldRule{Requirement: Optional, Pattern: regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`), Format: "IBAN in electronic form"}
+	forbiddenRule = FieldRule{Requirement: Forbidden}
+)
+
+func required(pattern string, format string) FieldRule {
+	return FieldRule{Requirement: Required, Pattern: regexp.MustCompile(pattern), Format: format}
+}
+
+func optional(pattern string, format string) FieldRule {
+	return FieldRule{Requirement: Optional, Pattern: regexp.MustCompile(pattern), Format: format}
+}
+
+// countryRules is based on the account API documentation, an account number or IBAN that is optional
+// is generated by the account API when it is not sent.
+var countryRules = map[string]CountryRule{
+	"GB": {
+		BankID:        required(`^[0-9]{6}$`, "6 digit sort code"),
+		BankIDCode:    required(`^GBDSC$`, "GBDSC"),
+		Bic:           bicRule,
+		AccountNumber: optional(`^[0-9]{8}$`, "8 digits"),
+		Iban:          ibanRule,
+	},
+	"AU": {
+		BankID:        optional(`^[0-9]{6}$`, "6 digit BSB code"),
+		BankIDCode:    required(`^AUBSB$`, "AUBSB"),
+		Bic:           bicRule,
+		AccountNumber: optional(`^[0-9]{6,10}$`, "6 to 10 digits"),
+		Iban:          forbiddenRule,
+	},
+	"BE": {
+		BankID:        required(`^[0-9]{3}$`, "3 digits"),
+		BankIDCode:    required(`^BE$`, "BE"),
+		Bic:           optionalBic,
+		AccountNumber: optional(`^[0-9]{7}$`, "7 digits"),
+		Iban:          ibanRule,
+	},
+	"CA": {
+		BankID:        optional(`^0[0-9]{8}$`, "9 digits starting with 0"),
+		BankIDCode:    required(`^CACPA$`, "CACPA"),
+		Bic:           bicRule,
+		AccountNumber: optional(`^[0-9]{7,12}$`, "7 to 12 digits"),
+		Iban:          forbiddenRule,
+	},
+	"FR": {
+		BankID:        required(`^[0-9]{10}$`, "10 digits"),
+		BankIDCode:    required(`^FR$`, "FR"),
+		Bic:           optionalBic,
+		AccountNumber: optional(`^[0-9A-Z]{10}$`, "10 characters"),
+		Iban:          ibanRule,
+	},
+	"DE": {
+		BankID:        required(`^[0-9]{8}$`, "8 digit Bankleitzahl"),
+		BankIDCode:    required(`^DEBLZ$`, "DEBLZ"),
+		Bic:           optionalBic,
+		AccountNumber: optional(`^[0-9]{7}$`, "7 digits"),
+		Iban:          ibanRule,
+	},
+	// the Italian bank ID is the 5 digit ABI followed by the 5 digit CAB, as laid out in the BBAN by package iban
+	"IT": {
+		BankID:        required(`^[0-9]{10}$`, "10 digits, ABI and CAB"),
+		BankIDCode:    required(`^ITNCC$`, "ITNCC"),
+		Bic:           optionalBic,
+		AccountNumber: optional(`^[0-9]{12}$`, "12 digits"),
+		Iban:          ibanRule,
+	},
+	"NL": {
+		BankID:        forbiddenRule,
+		BankIDCode:    forbiddenRule,
+		Bic:           bicRule,
+		AccountNumber: optional(`^[0-9]{10}$`, "10 digits"),
+		Iban:          ibanRule,
+	},
+	"ES": {
+		BankID:        required(`^[0-9]{8}$`, "8 digits"),
+		BankIDCode:    required(`^ESNCC$`, "ESNCC"),
+		Bic:           optionalBic,
+		AccountNumber: optional(`^[0-9]{10}$`, "10 digits"),
+		Iban:          ibanRule,
+	},
+	"US": {
+		BankID:        required(`^[0-9]{9}$`, "9 digit ABA routing number"),
+		BankIDCode:    required(`^USABA$`, "USABA"),
+		Bic:           bicRule,
+		AccountNumber: optional(`^[0-9]{6,17}$`, "6 to 17 digits"),
+		Iban:          forbiddenRule,
+	},
+}
+
+// RuleFor returns the rules of a country, the second value is false when there are no rules for it.
+func RuleFor(country string) (CountryRule, bool) {
+	rule, ok := countryRules[country]
+	rule.Country = country
+	return rule, ok
+}
+
+// Countries returns the countries with rules, sorted alphabetically.
+func Countries() []string {
+	countries := make([]string, 0, len(countryRules))
+	for country := range countryRules {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+
+	return countries
+}
+
+// Validate checks attributes against the rules of its Country, it returns models.ValidationErrors when
+// a required field is missing, a forbidden one is sent or a field does not match its format.
+func Validate(attributes *models.AccountAttributes) error {
+	if attributes == nil {
+		return models.ValidationErrors{{Field: "attributes", Message: "is required"}}
+	}
+
+	if attributes.Country == nil || *attributes.Country == "" {
+		return models.ValidationErrors{{Field: "country", Message: "is required"}}
+	}
+
+	rule, ok := RuleFor(*attributes.Country)
+	if !ok {
+		return models.ValidationErrors{{Field: "country", Message: "has no rules defined"}}
+	}
+
+	values := map[string]string{
+		"bank_id":        attributes.BankID,
+		"bank_id_code":   attributes.BankIDCode,
+		"bic":            attributes.Bic,
+		"account_number": attributes.AccountNumber,
+		"iban":           attributes.Iban,
+	}
+
+	fieldRules := rule.Fields()
+
+	var errs models.ValidationErrors
+	for _, field := range []string{"bank_id", "bank_id_code", "bic", "account_number", "iban"} {
+		if fieldErr, ok := validateField(field, values[field], fieldRules[field]); !ok {
+			errs = append(errs, fieldErr)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func validateField(field string, value string, rule FieldRule) (models.FieldError, bool) {
+	switch {
+	case value == "" && rule.Requirement == Required:
+		return models.FieldError{Field: field, Message: "is required"}, false
+	case value != "" && rule.Requirement == Forbidden:
+		return models.FieldError{Field: field, Message: "is not allowed"}, false
+	case value != "" && rule.Pattern != nil && !rule.Pattern.MatchString(value):
+		return models.FieldError{Field: field, Message: "must be " + rule.Format}, false
+	}
+
+	return models.FieldError{}, true
+}
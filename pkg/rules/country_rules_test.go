@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"accountapi-lib-form3/pkg/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func getAttributesStub(country string) *models.AccountAttributes {
+	return &models.AccountAttributes{
+		Country:    &country,
+		BankID:     "400302",
+		BankIDCode: "GBDSC",
+		Bic:        "NWBKGB42",
+	}
+}
+
+func fieldsOf(err error) []string {
+	var validationErrs models.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	var fields []string
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, fieldErr.Field+" "+fieldErr.Message)
+	}
+
+	return fields
+}
+
+func TestRules_ShouldCoverRequiredCountries(t *testing.T) {
+	want := []string{"AU", "BE", "CA", "DE", "ES", "FR", "GB", "IT", "NL", "US"}
+
+	got := Countries()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wanted: %v\n got: %v", want, got)
+	}
+}
+
+func TestRules_ShouldAcceptValidAttributes(t *testing.T) {
+	dataTable := []struct {
+		testName   string
+		attributes *models.AccountAttributes
+	}{
+		{"GB", getAttributesStub("GB")},
+		{"DE", &models.AccountAttributes{Country: strPtr("DE"), BankID: "37040044", BankIDCode: "DEBLZ", AccountNumber: "0532013"}},
+		{"NL", &models.AccountAttributes{Country: strPtr("NL"), Bic: "ABNANL2A", Iban: "NL91ABNA0417164300"}},
+		{"US", &models.AccountAttributes{Country: strPtr("US"), BankID: "021000021", BankIDCode: "USABA", Bic: "CHASUS33"}},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			if err := Validate(v.attributes); err != nil {
+				t.Errorf("wanted: nil\n got: %v", err)
+			}
+		})
+	}
+}
+
+func TestRules_ShouldReportRequiredForbiddenAndFormat(t *testing.T) {
+	dataTable := []struct {
+		testName   string
+		attributes *models.AccountAttributes
+		want       []string
+	}{
+		{"missingBic", &models.AccountAttributes{Country: strPtr("GB"), BankID: "400302", BankIDCode: "GBDSC"}, []string{"bic is required"}},
+		{"wrongCode", &models.AccountAttributes{Country: strPtr("DE"), BankID: "37040044", BankIDCode: "GBDSC"}, []string{"bank_id_code must be DEBLZ"}},
+		{"forbiddenBankID", &models.AccountAttributes{Country: strPtr("NL"), BankID: "123", Bic: "ABNANL2A"}, []string{"bank_id is not allowed"}},
+		{"forbiddenIban", &models.AccountAttributes{Country: strPtr("AU"), BankIDCode: "AUBSB", Bic: "NATAAU33", Iban: "AU00"}, []string{"iban is not allowed"}},
+		{"shortBankID", &models.AccountAttributes{Country: strPtr("ES"), BankID: "123", BankIDCode: "ESNCC"}, []string{"bank_id must be 8 digits"}},
+		{"unknownCountry", &models.AccountAttributes{Country: strPtr("JP")}, []string{"country has no rules defined"}},
+		{"missingCountry", &models.AccountAttributes{}, []string{"country is required"}},
+		{"missingAttributes", nil, []string{"attributes is required"}},
+		{"longItalianBankID", &models.AccountAttributes{Country: strPtr("IT"), BankID: "05428111011", BankIDCode: "ITNCC"}, []string{"bank_id must be 10 digits, ABI and CAB"}},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			got := fieldsOf(Validate(v.attributes))
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("wanted: %v\n got: %v", v.want, got)
+			}
+		})
+	}
+}
+
+func TestRules_ShouldExposeRequirementsPerField(t *testing.T) {
+	rule, ok := RuleFor("NL")
+
+	if !ok || rule.Country != "NL" {
+		t.Fatalf("wanted: NL rules\n got: %v %v", rule, ok)
+	}
+	if got := rule.Fields()["bank_id"].Requirement.String(); got != "forbidden" {
+		t.Errorf("bank_id wanted: forbidden\n got: %s", got)
+	}
+	if got := rule.Fields()["bic"].Requirement.String(); got != "required" {
+		t.Errorf("bic wanted: required\n got: %s", got)
+	}
+	if got := rule.Fields()["iban"].Requirement.String(); got != "optional" {
+		t.Errorf("iban wanted: optional\n got: %s", got)
+	}
+}
+
+func strPtr(value string) *string {
+	return &value
+}
@@ -0,0 +1,84 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+var errNoPEMBlock = errors.New("no PEM block found")
+
+// LoadPrivateKey reads a RSA or Ed25519 private key from a PEM file.
+func LoadPrivateKey(pemFile string) (crypto.PrivateKey, error) {
+	pemBytes, err := ioutil.ReadFile(pemFile) // #nosec G304 -- path is provided by the library user
+	if err != nil {
+		return nil, fmt.Errorf("failed reading private key: %w", err)
+	}
+
+	return ParsePrivateKey(pemBytes)
+}
+
+// ParsePrivateKey decodes a RSA or Ed25519 private key, both PKCS #1 ("RSA PRIVATE KEY") and
+// PKCS #8 ("PRIVATE KEY") encodings are supported.
+func ParsePrivateKey(pemBytes []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed parsing private key: %w", errNoPEMBlock)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing private key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey, ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("failed parsing private key: unsupported key type %T", key)
+	}
+}
+
+// LoadPublicKey reads a RSA or Ed25519 public key from a PEM file.
+func LoadPublicKey(pemFile string) (crypto.PublicKey, error) {
+	pemBytes, err := ioutil.ReadFile(pemFile) // #nosec G304 -- path is provided by the library user
+	if err != nil {
+		return nil, fmt.Errorf("failed reading public key: %w", err)
+	}
+
+	return ParsePublicKey(pemBytes)
+}
+
+// ParsePublicKey decodes a RSA or Ed25519 public key, both PKCS #1 ("RSA PUBLIC KEY") and
+// PKIX ("PUBLIC KEY") encodings are supported.
+func ParsePublicKey(pemBytes []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed parsing public key: %w", errNoPEMBlock)
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing public key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("failed parsing public key: unsupported key type %T", key)
+	}
+}
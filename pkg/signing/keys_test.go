@@ -0,0 +1,92 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestKeys_ShouldParseSupportedEncodings(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	rsaPKCS8, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	edPKCS8, _ := x509.MarshalPKCS8PrivateKey(edPrivate)
+	rsaPKIX, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	edPKIX, _ := x509.MarshalPKIXPublicKey(edPublic)
+
+	privateTable := []struct {
+		testName string
+		block    *pem.Block
+		want     string
+	}{
+		{"rsaPKCS1", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, "*rsa.PrivateKey"},
+		{"rsaPKCS8", &pem.Block{Type: "PRIVATE KEY", Bytes: rsaPKCS8}, "*rsa.PrivateKey"},
+		{"ed25519PKCS8", &pem.Block{Type: "PRIVATE KEY", Bytes: edPKCS8}, "ed25519.PrivateKey"},
+	}
+
+	for _, v := range privateTable {
+		t.Run(v.testName, func(t *testing.T) {
+			got, err := ParsePrivateKey(pem.EncodeToMemory(v.block))
+			if err != nil || reflect.TypeOf(got).String() != v.want {
+				t.Errorf("wanted: %s\n got: %T %v", v.want, got, err)
+			}
+		})
+	}
+
+	publicTable := []struct {
+		testName string
+		block    *pem.Block
+		want     string
+	}{
+		{"rsaPKCS1", &pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)}, "*rsa.PublicKey"},
+		{"rsaPKIX", &pem.Block{Type: "PUBLIC KEY", Bytes: rsaPKIX}, "*rsa.PublicKey"},
+		{"ed25519PKIX", &pem.Block{Type: "PUBLIC KEY", Bytes: edPKIX}, "ed25519.PublicKey"},
+	}
+
+	for _, v := range publicTable {
+		t.Run(v.testName, func(t *testing.T) {
+			got, err := ParsePublicKey(pem.EncodeToMemory(v.block))
+			if err != nil || reflect.TypeOf(got).String() != v.want {
+				t.Errorf("wanted: %s\n got: %T %v", v.want, got, err)
+			}
+		})
+	}
+}
+
+func TestKeys_ShouldLoadKeysFromFiles(t *testing.T) {
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	edPKCS8, _ := x509.MarshalPKCS8PrivateKey(edPrivate)
+	edPKIX, _ := x509.MarshalPKIXPublicKey(edPublic)
+
+	dir := t.TempDir()
+	privateFile := filepath.Join(dir, "private.pem")
+	publicFile := filepath.Join(dir, "public.pem")
+	_ = ioutil.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edPKCS8}), 0600)
+	_ = ioutil.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: edPKIX}), 0600)
+
+	if _, err := LoadPrivateKey(privateFile); err != nil {
+		t.Errorf("wanted: private key\n got: %v", err)
+	}
+	if _, err := LoadPublicKey(publicFile); err != nil {
+		t.Errorf("wanted: public key\n got: %v", err)
+	}
+	if _, err := LoadPrivateKey(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Errorf("wanted: error for a missing file\n got: nil")
+	}
+}
+
+func TestKeys_ShouldFailWithoutPEMBlock(t *testing.T) {
+	if _, err := ParsePrivateKey([]byte("not a key")); err == nil {
+		t.Errorf("wanted: error\n got: nil")
+	}
+
+	if _, err := ParsePublicKey([]byte("not a key")); err == nil {
+		t.Errorf("wanted: error\n got: nil")
+	}
+}
@@ -0,0 +1,184 @@
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HeaderMode defines the header where the signature is sent.
+type HeaderMode int
+
+const (
+	// SignatureHeader sends the signature parameters in a Signature header.
+	SignatureHeader HeaderMode = iota
+	// AuthorizationHeader sends the signature parameters in an Authorization header with the Signature scheme.
+	AuthorizationHeader
+)
+
+const (
+	algorithmRSASHA256    = "rsa-sha256"
+	algorithmEd25519      = "ed25519"
+	requestTargetHeader   = "(request-target)"
+	hostHeader            = "host"
+	dateHeader            = "date"
+	digestHeader          = "digest"
+	digestAlgorithm       = "SHA-256="
+	signatureHeaderName   = "Signature"
+	authorizationHeader   = "Authorization"
+	authorizationScheme   = "Signature "
+	signatureParamsFormat = `keyId="%s",algorithm="%s",headers="%s",signature="%s"`
+)
+
+// Signer signs requests following the HTTP message signatures draft (draft-cavage-http-signatures). The
+// signature covers (request-target), host and date, plus a SHA-256 Digest header when the request has a body.
+type Signer struct {
+	keyID      string
+	key        crypto.PrivateKey
+	algorithm  string
+	headerMode HeaderMode
+	now        func() time.Time
+}
+
+// NewSigner creates a Signer for a RSA or Ed25519 private key, keyID identifies the key in the
+// account API. LoadPrivateKey allows to read the key from a PEM file.
+func NewSigner(keyID string, key crypto.PrivateKey, headerMode HeaderMode) (*Signer, error) {
+	var algorithm string
+
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		algorithm = algorithmRSASHA256
+	case ed25519.PrivateKey:
+		algorithm = algorithmEd25519
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return &Signer{
+		keyID:      keyID,
+		key:        key,
+		algorithm:  algorithm,
+		headerMode: headerMode,
+		now:        time.Now,
+	}, nil
+}
+
+// SignRequest adds Digest and Signature (or Authorization) headers to req. A Date header is added when it
+// is missing. The body is read through GetBody when it is available, otherwise it is buffered and restored.
+func (s *Signer) SignRequest(req *http.Request) error {
+	if req.Header.Get(dateHeader) == "" {
+		req.Header.Set(dateHeader, s.now().UTC().Format(http.TimeFormat))
+	}
+
+	headers := []string{requestTargetHeader, hostHeader, dateHeader}
+
+	body, err := readBody(req)
+	if err != nil {
+		return fmt.Errorf("failed signing request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set(digestHeader, digest(body))
+		headers = append(headers, digestHeader)
+	}
+
+	signature, err := s.sign([]byte(signingString(req, headers)))
+	if err != nil {
+		return fmt.Errorf("failed signing request: %w", err)
+	}
+
+	params := fmt.Sprintf(signatureParamsFormat, s.keyID, s.algorithm, strings.Join(headers, " "), signature)
+	if s.headerMode == AuthorizationHeader {
+		req.Header.Set(authorizationHeader, authorizationScheme+params)
+	} else {
+		req.Header.Set(signatureHeaderName, params)
+	}
+
+	return nil
+}
+
+func (s *Signer) sign(message []byte) (string, error) {
+	var signature []byte
+	var err error
+
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		hashed := sha256.Sum256(message)
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, message)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// signingString builds the string to sign, one "name: value" line per header in the given order.
+func signingString(req *http.Request, headers []string) string {
+	lines := make([]string, 0, len(headers))
+
+	for _, header := range headers {
+		var value string
+		switch header {
+		case requestTargetHeader:
+			value = strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+		case hostHeader:
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		default:
+			value = strings.Join(req.Header.Values(header), ", ")
+		}
+		lines = append(lines, header+": "+value)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return digestAlgorithm + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// readBody returns a copy of the request body, or nil when there is no body, without consuming it.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+
+		return ioutil.ReadAll(body)
+	}
+
+	bodyBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = req.Body.Close()
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(bodyBytes)), nil
+	}
+
+	return bodyBytes, nil
+}
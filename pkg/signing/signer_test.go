@@ -0,0 +1,142 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	keyIDStub = "75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8"
+	bodyStub  = `{"data":{"id":"ebb084cb-5cb7-49b5-b61c-ea0f7036e4b6"}}`
+)
+
+func getKeysStub(t *testing.T) map[string][2]interface{} {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed generating RSA key: %v", err)
+	}
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+
+	return map[string][2]interface{}{
+		"rsa":     {rsaKey, &rsaKey.PublicKey},
+		"ed25519": {edPrivate, edPublic},
+	}
+}
+
+func getRequestStub(body string) *http.Request {
+	if body == "" {
+		req, _ := http.NewRequest(http.MethodGet, "http://accountapi:8080/v1/organisation/accounts?page%5Bsize%5D=1", nil)
+		return req
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://accountapi:8080/v1/organisation/accounts", strings.NewReader(body))
+	return req
+}
+
+func TestSigner_ShouldBeVerifiedByVerifier(t *testing.T) {
+	for keyType, keys := range getKeysStub(t) {
+		for _, mode := range []HeaderMode{SignatureHeader, AuthorizationHeader} {
+			for _, body := range []string{"", bodyStub} {
+				signer, _ := NewSigner(keyIDStub, keys[0].(crypto.PrivateKey), mode)
+				verifier, _ := NewVerifier(keyIDStub, keys[1].(crypto.PublicKey))
+
+				req := getRequestStub(body)
+				if err := signer.SignRequest(req); err != nil {
+					t.Fatalf("%s: wanted: no error\n got: %v", keyType, err)
+				}
+
+				if err := verifier.Verify(req); err != nil {
+					t.Errorf("%s mode %d body %q: wanted: valid signature\n got: %v", keyType, mode, body, err)
+				}
+			}
+		}
+	}
+}
+
+func TestSigner_ShouldAddSignatureHeaders(t *testing.T) {
+	keys := getKeysStub(t)["ed25519"]
+	signer, _ := NewSigner(keyIDStub, keys[0].(crypto.PrivateKey), SignatureHeader)
+
+	req := getRequestStub(bodyStub)
+	_ = signer.SignRequest(req)
+
+	if req.Header.Get("Date") == "" {
+		t.Errorf("wanted: Date header\n got: none")
+	}
+	if !strings.HasPrefix(req.Header.Get("Digest"), "SHA-256=") {
+		t.Errorf("wanted: SHA-256 digest\n got: %s", req.Header.Get("Digest"))
+	}
+	want := `keyId="` + keyIDStub + `",algorithm="ed25519",headers="(request-target) host date digest"`
+	if got := req.Header.Get("Signature"); !strings.HasPrefix(got, want) {
+		t.Errorf("wanted prefix: %s\n got: %s", want, got)
+	}
+}
+
+func TestVerifier_ShouldRejectTamperedRequests(t *testing.T) {
+	keys := getKeysStub(t)["rsa"]
+	signer, _ := NewSigner(keyIDStub, keys[0].(crypto.PrivateKey), SignatureHeader)
+	verifier, _ := NewVerifier(keyIDStub, keys[1].(crypto.PublicKey))
+	otherVerifier, _ := NewVerifier("other", keys[1].(crypto.PublicKey))
+
+	dataTable := []struct {
+		testName string
+		tamper   func(req *http.Request) *http.Request
+		verifier *Verifier
+	}{
+		{"tamperedBody", func(req *http.Request) *http.Request {
+			tampered := getRequestStub(`{"data":{}}`)
+			tampered.Header = req.Header
+			return tampered
+		}, verifier},
+		{"tamperedDate", func(req *http.Request) *http.Request {
+			req.Header.Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+			return req
+		}, verifier},
+		{"tamperedTarget", func(req *http.Request) *http.Request {
+			req.URL.Path += "/other"
+			return req
+		}, verifier},
+		{"unknownKey", func(req *http.Request) *http.Request {
+			return req
+		}, otherVerifier},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			req := getRequestStub(bodyStub)
+			_ = signer.SignRequest(req)
+
+			err := v.verifier.Verify(v.tamper(req))
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("wanted: %v\n got: %v", ErrInvalidSignature, err)
+			}
+		})
+	}
+}
+
+func TestVerifier_ShouldRejectMissingSignature(t *testing.T) {
+	keys := getKeysStub(t)["ed25519"]
+	verifier, _ := NewVerifier(keyIDStub, keys[1].(crypto.PublicKey))
+
+	err := verifier.Verify(getRequestStub(""))
+
+	if !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("wanted: %v\n got: %v", ErrMissingSignature, err)
+	}
+}
+
+func TestSigner_ShouldRejectUnsupportedKeys(t *testing.T) {
+	if _, err := NewSigner(keyIDStub, "key", SignatureHeader); err == nil {
+		t.Errorf("wanted: error\n got: nil")
+	}
+
+	if _, err := NewVerifier(keyIDStub, "key"); err == nil {
+		t.Errorf("wanted: error\n got: nil")
+	}
+}
@@ -0,0 +1,132 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrMissingSignature is returned when the request carries neither a Signature nor an Authorization header.
+	ErrMissingSignature = errors.New("missing signature")
+	// ErrInvalidSignature is returned when the signature, its parameters or the digest do not match the request.
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Verifier checks signatures created by Signer, it is the counterpart used by tests and fake servers.
+type Verifier struct {
+	keyID string
+	key   crypto.PublicKey
+}
+
+// NewVerifier creates a Verifier for a RSA or Ed25519 public key, only signatures made with keyID are accepted.
+// LoadPublicKey allows to read the key from a PEM file.
+func NewVerifier(keyID string, key crypto.PublicKey) (*Verifier, error) {
+	switch key.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	return &Verifier{
+		keyID: keyID,
+		key:   key,
+	}, nil
+}
+
+// Verify checks the signature of req and, when the request has a body, that its Digest header matches it.
+func (v *Verifier) Verify(req *http.Request) error {
+	params, err := signatureParams(req)
+	if err != nil {
+		return err
+	}
+
+	if params["keyId"] != v.keyID {
+		return fmt.Errorf("%w: unknown key %q", ErrInvalidSignature, params["keyId"])
+	}
+
+	headers := strings.Fields(params["headers"])
+	if len(headers) == 0 {
+		headers = []string{dateHeader}
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if body != nil {
+		if !contains(headers, digestHeader) {
+			return fmt.Errorf("%w: digest is not signed", ErrInvalidSignature)
+		}
+		if req.Header.Get(digestHeader) != digest(body) {
+			return fmt.Errorf("%w: digest does not match body", ErrInvalidSignature)
+		}
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	message := []byte(signingString(req, headers))
+
+	switch key := v.key.(type) {
+	case *rsa.PublicKey:
+		if params["algorithm"] != algorithmRSASHA256 {
+			return fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidSignature, params["algorithm"])
+		}
+		hashed := sha256.Sum256(message)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], signature); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+		}
+	case ed25519.PublicKey:
+		if params["algorithm"] != algorithmEd25519 {
+			return fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidSignature, params["algorithm"])
+		}
+		if !ed25519.Verify(key, message, signature) {
+			return ErrInvalidSignature
+		}
+	}
+
+	return nil
+}
+
+// signatureParams parses keyId, algorithm, headers and signature from the Signature header, or from the
+// Authorization header when the former is missing.
+func signatureParams(req *http.Request) (map[string]string, error) {
+	value := req.Header.Get(signatureHeaderName)
+	if value == "" {
+		authorization := req.Header.Get(authorizationHeader)
+		if !strings.HasPrefix(authorization, authorizationScheme) {
+			return nil, ErrMissingSignature
+		}
+		value = strings.TrimPrefix(authorization, authorizationScheme)
+	}
+
+	params := map[string]string{}
+	for _, param := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: malformed parameter %q", ErrInvalidSignature, param)
+		}
+		params[parts[0]] = strings.Trim(parts[1], `"`)
+	}
+
+	return params, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,147 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Headers defined by W3C trace context, plus the de facto standard request ID header.
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+	RequestIDHeader   = "X-Request-ID"
+	traceParentFormat = "00-%s-%s-%02x"
+	sampledFlag       = 0x01
+)
+
+var ErrInvalidTraceParent = errors.New("invalid traceparent")
+
+// SpanContext identifies a span across services, it is what is propagated in traceparent and tracestate.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	Flags      byte
+	TraceState string
+}
+
+// IsValid tells whether both trace and span IDs are set, as W3C trace context forbids all-zero IDs.
+func (s SpanContext) IsValid() bool {
+	return s.TraceID != [16]byte{} && s.SpanID != [8]byte{}
+}
+
+// IsSampled tells whether the sampled flag is set.
+func (s SpanContext) IsSampled() bool {
+	return s.Flags&sampledFlag != 0
+}
+
+// TraceParent formats the span context as the value of a traceparent header of version 00.
+func (s SpanContext) TraceParent() string {
+	return fmt.Sprintf(traceParentFormat, hex.EncodeToString(s.TraceID[:]), hex.EncodeToString(s.SpanID[:]), s.Flags)
+}
+
+// ParseTraceParent parses the value of a traceparent header. Versions other than 00 are accepted as long as
+// they start with the fields defined by version 00, as required by W3C trace context.
+func ParseTraceParent(value string) (SpanContext, error) {
+	var spanContext SpanContext
+
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return spanContext, ErrInvalidTraceParent
+	}
+
+	if err := decodeHex(parts[1], spanContext.TraceID[:]); err != nil {
+		return spanContext, err
+	}
+	if err := decodeHex(parts[2], spanContext.SpanID[:]); err != nil {
+		return spanContext, err
+	}
+
+	var flags [1]byte
+	if err := decodeHex(parts[3], flags[:]); err != nil {
+		return spanContext, err
+	}
+	spanContext.Flags = flags[0]
+
+	if !spanContext.IsValid() {
+		return spanContext, ErrInvalidTraceParent
+	}
+
+	return spanContext, nil
+}
+
+// decodeHex only accepts lowercase hexadecimal values of the exact length of dst.
+func decodeHex(value string, dst []byte) error {
+	if len(value) != hex.EncodedLen(len(dst)) || strings.ToLower(value) != value {
+		return ErrInvalidTraceParent
+	}
+
+	if _, err := hex.Decode(dst, []byte(value)); err != nil {
+		return ErrInvalidTraceParent
+	}
+
+	return nil
+}
+
+// Extract returns a copy of ctx carrying the span context and request ID found in header, so a service can
+// continue the trace of an incoming request. Invalid traceparent headers are ignored.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	if spanContext, err := ParseTraceParent(header.Get(TraceParentHeader)); err == nil {
+		spanContext.TraceState = header.Get(TraceStateHeader)
+		ctx = ContextWithRemoteSpanContext(ctx, spanContext)
+	}
+
+	if requestID := header.Get(RequestIDHeader); requestID != "" {
+		ctx = WithRequestID(ctx, requestID)
+	}
+
+	return ctx
+}
+
+// Inject sets traceparent and tracestate from the span context carried by ctx, when it is valid, and
+// X-Request-ID from the request ID carried by ctx, a new one is generated when there is none.
+func Inject(ctx context.Context, header http.Header) {
+	if spanContext := SpanContextFromContext(ctx); spanContext.IsValid() {
+		header.Set(TraceParentHeader, spanContext.TraceParent())
+		if spanContext.TraceState != "" {
+			header.Set(TraceStateHeader, spanContext.TraceState)
+		}
+	}
+
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		requestID = NewRequestID()
+	}
+	header.Set(RequestIDHeader, requestID)
+}
+
+// NewRequestID generates a random ID formatted as a version 4 UUID.
+func NewRequestID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	encoded := hex.EncodeToString(id[:])
+	return encoded[:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:]
+}
+
+func newTraceID() [16]byte {
+	var id [16]byte
+	for id == [16]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() [8]byte {
+	var id [8]byte
+	for id == [8]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RecordedSpan is a span kept by a Recorder.
+type RecordedSpan struct {
+	Name         string
+	SpanContext  SpanContext
+	ParentSpanID [8]byte
+	Attributes   map[string]interface{}
+	Errors       []error
+	Start        time.Time
+	End          time.Time
+}
+
+// Recorder is a Tracer keeping spans in memory, it is meant for tests. Spans are sampled and continue the
+// trace of the span context carried by the context they are started from.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []RecordedSpan
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+
+	spanContext := SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Flags: sampledFlag, TraceState: parent.TraceState}
+	if !parent.IsValid() {
+		spanContext.TraceID = newTraceID()
+	}
+
+	span := &recordingSpan{
+		recorder: r,
+		span: RecordedSpan{
+			Name:         name,
+			SpanContext:  spanContext,
+			ParentSpanID: parent.SpanID,
+			Attributes:   map[string]interface{}{},
+			Start:        time.Now(),
+		},
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// Spans returns the spans that have ended, in the order they ended.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]RecordedSpan(nil), r.spans...)
+}
+
+// Reset discards every recorded span.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = nil
+}
+
+type recordingSpan struct {
+	recorder *Recorder
+	mu       sync.Mutex
+	span     RecordedSpan
+	ended    bool
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ended {
+		s.span.Attributes[key] = value
+	}
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ended && err != nil {
+		s.span.Errors = append(s.span.Errors, err)
+	}
+}
+
+// End records the span, only the first invocation has effect.
+func (s *recordingSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.span.End = time.Now()
+	span := s.span
+	s.mu.Unlock()
+
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.spans = append(s.recorder.spans, span)
+}
+
+func (s *recordingSpan) SpanContext() SpanContext {
+	return s.span.SpanContext
+}
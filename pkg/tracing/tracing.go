@@ -0,0 +1,102 @@
+// Package tracing defines the spans this library opens around every account API operation, and the
+// propagation of W3C trace context (traceparent and tracestate) and X-Request-ID headers. It does not depend
+// on any tracing library, an adapter implementing Tracer can forward spans to one.
+package tracing
+
+import (
+	"context"
+)
+
+// Tracer starts spans, the returned context carries the new span, so it becomes the parent of spans
+// started from it and its span context is propagated to the account API.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a unit of work, every method must be safe to invoke after End.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+	SpanContext() SpanContext
+}
+
+// Attribute keys set by this library.
+const (
+	AttributeOperation  = "accountapi.operation"
+	AttributeAccountID  = "accountapi.account_id"
+	AttributeMethod     = "http.method"
+	AttributeStatusCode = "http.status_code"
+)
+
+type nopTracer struct{}
+
+// Start returns ctx unchanged, so a span context received from an upstream service is still propagated.
+func (nopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, nopSpan{spanContext: SpanContextFromContext(ctx)}
+}
+
+// NopTracer returns a Tracer whose spans record nothing.
+func NopTracer() Tracer {
+	return nopTracer{}
+}
+
+type nopSpan struct {
+	spanContext SpanContext
+}
+
+func (nopSpan) SetAttribute(string, interface{}) {}
+
+func (nopSpan) RecordError(error) {}
+
+func (nopSpan) End() {}
+
+func (n nopSpan) SpanContext() SpanContext { return n.spanContext }
+
+type spanKey struct{}
+
+type remoteSpanContextKey struct{}
+
+type requestIDKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span, tracers invoke it when a span is started.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or a span recording nothing when there is none.
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+
+	return nopSpan{spanContext: SpanContextFromContext(ctx)}
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx carrying a span context received from an upstream
+// service, e.g. obtained through Extract. It is used as parent when no span has been started.
+func ContextWithRemoteSpanContext(ctx context.Context, spanContext SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanContextKey{}, spanContext)
+}
+
+// SpanContextFromContext returns the span context of the span carried by ctx or, when there is none, the
+// remote span context.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span.SpanContext()
+	}
+
+	spanContext, _ := ctx.Value(remoteSpanContextKey{}).(SpanContext)
+	return spanContext
+}
+
+// WithRequestID returns a copy of ctx carrying the ID sent in the X-Request-ID header.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, it is empty when there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+const traceParentStub = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTraceParent_ShouldParseAndFormat(t *testing.T) {
+	got, err := ParseTraceParent(traceParentStub)
+
+	if err != nil {
+		t.Fatalf("wanted: nil\n got: %v", err)
+	}
+	if !got.IsValid() || !got.IsSampled() {
+		t.Errorf("wanted: valid and sampled\n got: %v", got)
+	}
+	if got.TraceParent() != traceParentStub {
+		t.Errorf("wanted: %s\n got: %s", traceParentStub, got.TraceParent())
+	}
+}
+
+func TestTraceParent_ShouldRejectInvalidValues(t *testing.T) {
+	dataTable := []struct {
+		testName string
+		value    string
+	}{
+		{"empty", ""},
+		{"forbiddenVersion", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{"extraFieldsInVersion00", traceParentStub + "-extra"},
+		{"zeroTraceID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{"zeroSpanID", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{"shortSpanID", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01"},
+	}
+
+	for _, v := range dataTable {
+		t.Run(v.testName, func(t *testing.T) {
+			if _, err := ParseTraceParent(v.value); !errors.Is(err, ErrInvalidTraceParent) {
+				t.Errorf("wanted: %v\n got: %v", ErrInvalidTraceParent, err)
+			}
+		})
+	}
+}
+
+func TestPropagation_ShouldExtractAndInjectHeaders(t *testing.T) {
+	incoming := http.Header{}
+	incoming.Set(TraceParentHeader, traceParentStub)
+	incoming.Set(TraceStateHeader, "congo=t61rcWkgMzE")
+	incoming.Set(RequestIDHeader, "request-1")
+
+	ctx := Extract(context.Background(), incoming)
+	outgoing := http.Header{}
+	Inject(ctx, outgoing)
+
+	for _, header := range []string{TraceParentHeader, TraceStateHeader, RequestIDHeader} {
+		if outgoing.Get(header) != incoming.Get(header) {
+			t.Errorf("%s wanted: %s\n %s got: %s", header, incoming.Get(header), header, outgoing.Get(header))
+		}
+	}
+}
+
+func TestNewRequestID_ShouldFormatVersion4UUID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, second := NewRequestID(), NewRequestID()
+
+	if !pattern.MatchString(first) || first == second {
+		t.Errorf("wanted: different version 4 UUIDs\n got: %s %s", first, second)
+	}
+}
+
+func TestNopTracer_ShouldKeepRemoteSpanContext(t *testing.T) {
+	remote, _ := ParseTraceParent(traceParentStub)
+	ctx := ContextWithRemoteSpanContext(context.Background(), remote)
+
+	gotCtx, span := NopTracer().Start(ctx, "operation")
+	span.SetAttribute("key", "value")
+	span.End()
+
+	if SpanContextFromContext(gotCtx) != remote || span.SpanContext() != remote {
+		t.Errorf("wanted: %v\n got: %v", remote, span.SpanContext())
+	}
+}
+
+func TestRecorder_ShouldRecordChildSpansOfRemoteParent(t *testing.T) {
+	subject := NewRecorder()
+	remote, _ := ParseTraceParent(traceParentStub)
+	ctx := ContextWithRemoteSpanContext(context.Background(), remote)
+
+	parentCtx, parent := subject.Start(ctx, "parent")
+	childCtx, child := subject.Start(parentCtx, "child")
+	SpanFromContext(childCtx).SetAttribute("key", "value")
+	child.RecordError(errors.New("failed"))
+	child.End()
+	parent.End()
+	parent.SetAttribute("ignored", "after end")
+	parent.End()
+
+	spans := subject.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("spans wanted: 2\n spans got: %d", len(spans))
+	}
+
+	gotChild, gotParent := spans[0], spans[1]
+	if gotChild.Name != "child" || gotChild.Attributes["key"] != "value" || len(gotChild.Errors) != 1 {
+		t.Errorf("wanted: child span with attribute and error\n got: %v", gotChild)
+	}
+	if gotChild.ParentSpanID != gotParent.SpanContext.SpanID || gotParent.ParentSpanID != remote.SpanID {
+		t.Errorf("wanted: child of parent of remote span\n got: %v %v", gotChild, gotParent)
+	}
+	if gotChild.SpanContext.TraceID != remote.TraceID || gotParent.SpanContext.TraceID != remote.TraceID {
+		t.Errorf("wanted: trace ID %x\n got: %x %x", remote.TraceID, gotChild.SpanContext.TraceID, gotParent.SpanContext.TraceID)
+	}
+	if _, ok := gotParent.Attributes["ignored"]; ok {
+		t.Errorf("wanted: attributes after End ignored")
+	}
+}
+
+func TestRecorder_ShouldStartNewTraceWithoutParent(t *testing.T) {
+	subject := NewRecorder()
+
+	_, span := subject.Start(context.Background(), "root")
+	span.End()
+
+	if got := subject.Spans()[0]; !got.SpanContext.IsValid() || got.ParentSpanID != [8]byte{} {
+		t.Errorf("wanted: valid root span\n got: %v", got)
+	}
+
+	subject.Reset()
+	if got := len(subject.Spans()); got != 0 {
+		t.Errorf("wanted: 0 spans after Reset\n got: %d", got)
+	}
+}